@@ -3,9 +3,9 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -14,8 +14,10 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
 	"github.com/example/self-healing-nodepool/pkg/collector"
 	"github.com/example/self-healing-nodepool/pkg/controller"
+	"github.com/example/self-healing-nodepool/pkg/correlation"
 	"github.com/example/self-healing-nodepool/pkg/decision"
 	"github.com/example/self-healing-nodepool/pkg/remediation"
 	"github.com/example/self-healing-nodepool/pkg/scorer"
@@ -32,8 +34,36 @@ func init() {
 }
 
 func main() {
-	var metricsAddr string
+	var (
+		metricsAddr    string
+		promConfig     collector.PrometheusConfig
+		signalCacheTTL time.Duration
+		enableNPD      bool
+		signalMaxAge   time.Duration
+		minCoverage    float64
+		evalInterval   time.Duration
+		leaderElect    bool
+		systemic       decision.SystemicBreakerConfig
+		cloudProvider  string
+		cloudConfig    string
+	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&leaderElect, "leader-elect", false, "Enable leader election so only one replica remediates at a time.")
+	flag.StringVar(&promConfig.URL, "prometheus-url", "http://prometheus-service:9090", "The address of the Prometheus HTTP API.")
+	flag.DurationVar(&promConfig.Timeout, "prometheus-timeout", 10*time.Second, "Timeout for each Prometheus query.")
+	flag.StringVar(&promConfig.BearerTokenFile, "prometheus-bearer-token-file", "", "File containing a bearer token for Prometheus.")
+	flag.StringVar(&promConfig.TLS.CAFile, "prometheus-ca-file", "", "CA bundle used to verify the Prometheus server certificate.")
+	flag.DurationVar(&signalCacheTTL, "signal-cache-ttl", 30*time.Second, "How long a node pool signal scrape is reused across reconciles.")
+	flag.BoolVar(&promConfig.TLS.InsecureSkipVerify, "prometheus-insecure-skip-verify", false, "Skip verification of the Prometheus server certificate.")
+	flag.DurationVar(&signalMaxAge, "signal-max-age", 10*time.Minute, "Signals older than this are treated as unknown.")
+	flag.Float64Var(&minCoverage, "min-signal-coverage", 0.5, "Share of the scoring weight that must be backed by fresh signals before a node is evaluated.")
+	flag.DurationVar(&evalInterval, "evaluation-interval", time.Minute, "How often each node is re-scored. Must be well below the policy's evaluation window.")
+	flag.Float64Var(&systemic.MaxUnhealthyFraction, "systemic-unhealthy-fraction", 0.3, "Halt all remediation of a pool, or the cluster, when more than this share of its nodes is unhealthy.")
+	flag.IntVar(&systemic.MinUnhealthyNodes, "systemic-min-unhealthy-nodes", 3, "Number of unhealthy nodes below which remediation is never halted as systemic.")
+	flag.DurationVar(&systemic.Window, "systemic-window", 10*time.Minute, "How long a node's health counts towards the systemic failure check.")
+	flag.BoolVar(&enableNPD, "enable-node-problem-detector", false, "Use node-problem-detector conditions and events as signals.")
+	flag.StringVar(&cloudProvider, "cloud-provider", "", "Cloud provider that reboots and replaces nodes and reports node pool sizes, one of "+strings.Join(cloud.Names(), ", ")+". None if empty.")
+	flag.StringVar(&cloudConfig, "cloud-config", "", "Provider-specific configuration of the cloud provider, e.g. the base URL of the webhook provider.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -48,6 +78,8 @@ func main() {
 			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress: ":8081",
+		LeaderElection:         leaderElect,
+		LeaderElectionID:       "self-healing-nodepool.infra.example.com",
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -55,9 +87,76 @@ func main() {
 	}
 
 	// Dependencies
-	promCollector := collector.NewPrometheusCollector("http://prometheus-service:9090")
-	defaultScorer := scorer.DefaultScorer()
+	promCollector, err := collector.NewPrometheusCollector(promConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create prometheus collector")
+		os.Exit(1)
+	}
+	conditionsCollector := collector.NewNodeConditionsCollector(mgr.GetClient(), collector.NodeConditionsConfig{})
+	eventsCollector := collector.NewKubeletEventsCollector(collector.KubeletEventsConfig{})
+	if err := eventsCollector.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up events collector")
+		os.Exit(1)
+	}
+	// The breaker sits below the cache so it only sees real upstream calls. A collection runs
+	// its queries in sequence, so it is only slow once it took longer than all of them may.
+	promBreaker := collector.NewCircuitBreaker(promCollector, collector.CircuitBreakerConfig{
+		Name:     "prometheus",
+		SlowCall: promConfig.Timeout * time.Duration(promCollector.QueriesPerCollection()),
+	})
+	sources := []collector.Source{
+		{
+			Name:      "prometheus",
+			Collector: collector.NewCachedCollector(promBreaker, collector.ClientNodeLister(mgr.GetClient()), signalCacheTTL),
+		},
+		{Name: "conditions", Collector: conditionsCollector},
+		{Name: "events", Collector: eventsCollector},
+	}
+	weights := scorer.DefaultWeights()
+	if enableNPD {
+		npdCollector := collector.NewNPDCollector(mgr.GetClient(), collector.NPDConfig{})
+		if err := npdCollector.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up node-problem-detector collector")
+			os.Exit(1)
+		}
+		sources = append(sources, collector.Source{Name: "npd", Collector: npdCollector})
+		for metric, w := range scorer.NodeProblemDetectorWeights() {
+			weights[metric] = w
+		}
+	}
+	signalCollector := collector.NewCompositeCollector(
+		// Prefer direct kubelet evidence from events over the Prometheus ratio.
+		map[scorer.MetricName][]string{
+			scorer.MetricKubeletErrors: {"events", "prometheus"},
+		},
+		sources...,
+	)
 	decisionEngine := decision.NewEngine()
+	decisionEngine.Telemetry = promBreaker
+	decisionEngine.Systemic = decision.NewSystemicBreaker(systemic)
+
+	var provider cloud.Provider
+	if cloudProvider != "" {
+		if provider, err = cloud.New(cloudProvider, cloudConfig); err != nil {
+			setupLog.Error(err, "unable to create cloud provider")
+			os.Exit(1)
+		}
+	}
+
+	// Refuse to start with policies that reboot or replace nodes without a cloud provider.
+	// Policies created later are reported through their ConfigValid condition instead.
+	ctx := ctrl.SetupSignalHandler()
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := mgr.GetAPIReader().List(ctx, &policies); err != nil {
+		setupLog.Error(err, "unable to list node healing policies")
+		os.Exit(1)
+	}
+	for i := range policies.Items {
+		if err := controller.ValidateCloudProvider(&policies.Items[i], provider); err != nil {
+			setupLog.Error(err, "policy cannot be applied, set --cloud-provider", "policy", policies.Items[i].Name)
+			os.Exit(1)
+		}
+	}
 
 	// Initialize Clientset for Eviction API
 	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
@@ -69,40 +168,39 @@ func main() {
 	remediator := &remediation.Executor{
 		Client:     mgr.GetClient(),
 		KubeClient: kubeClient,
-	}
-
-	// Default Policy (Hardcoded for MVP)
-	// IN PRODUCTION: This should be removed. The controller should watch for NodeHealingPolicy CRs
-	// created by the user and apply them dynamically to matching nodes.
-	// For this scaffold, we use a single hardcoded policy for simplicity.
-	policy := &v1alpha1.NodeHealingPolicy{
-		Spec: v1alpha1.NodeHealingPolicySpec{
-			Thresholds: v1alpha1.Thresholds{
-				UnhealthyScore:   0.6,
-				EvaluationWindow: metav1.Duration{Duration: 5 * time.Minute},
-			},
-			Remediation: v1alpha1.Remediation{
-				Cooldown: metav1.Duration{Duration: 30 * time.Minute},
-			},
-		},
+		Cloud:      provider,
 	}
 
 	if err = (&controller.NodeHealthReconciler{
-		Client:     mgr.GetClient(),
-		Log:        ctrl.Log.WithName("controllers").WithName("NodeHealth"),
-		Scheme:     mgr.GetScheme(),
-		Collector:  promCollector,
-		Scorer:     defaultScorer,
-		Decision:   decisionEngine,
-		Remediator: remediator,
-		Policy:     policy,
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("NodeHealth"),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("self-healing-nodepool"),
+		Collector:   signalCollector,
+		Staleness:   collector.StalenessPolicy{MaxAge: signalMaxAge},
+		Decision:    decisionEngine,
+		Correlation: correlation.NewAnalyzer(correlation.Config{}),
+		History:     decision.NewScoreHistory(0),
+		Remediator:  remediator,
+		Cloud:       provider,
+
+		DefaultWeights:     weights,
+		EvaluationInterval: evalInterval,
+		MinCoverage:        minCoverage,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NodeHealth")
 		os.Exit(1)
 	}
+	if err = (&controller.PolicyReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("NodeHealingPolicy"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "NodeHealingPolicy")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
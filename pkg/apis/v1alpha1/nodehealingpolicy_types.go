@@ -2,16 +2,33 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // NodeHealingPolicySpec defines the desired state of NodeHealingPolicy
 type NodeHealingPolicySpec struct {
+	// Mode is Enforce to remediate nodes, DryRun to only report what would have been done, or
+	// Disabled to leave the covered nodes alone.
+	// +kubebuilder:default=Enforce
+	// +optional
+	Mode PolicyMode `json:"mode,omitempty"`
+
 	// NodeSelector selects which nodes are covered by this policy.
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
+	// PoolID identifies the node pool at the cloud provider, used to read the live pool size.
+	// When empty the pool size is the number of nodes the selector matches.
+	// +optional
+	PoolID string `json:"poolID,omitempty"`
+
+	// Priority decides which policy applies to a node selected by several policies.
+	// The highest priority wins; ties go to the oldest policy, then to the lexically smallest name.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
 	// Thresholds defines the criteria for determining node health.
 	Thresholds Thresholds `json:"thresholds,omitempty"`
 
@@ -20,17 +37,67 @@ type NodeHealingPolicySpec struct {
 
 	// Limits defines safety guardrails for remediation.
 	Limits Limits `json:"limits,omitempty"`
+
+	// Scoring configures how raw signals are turned into a health score.
+	// +optional
+	Scoring Scoring `json:"scoring,omitempty"`
 }
 
+// PolicyMode decides whether a policy acts on its decisions.
+// +kubebuilder:validation:Enum=Enforce;DryRun;Disabled
+type PolicyMode string
+
+const (
+	// PolicyEnforce remediates nodes. It is the default.
+	PolicyEnforce PolicyMode = "Enforce"
+	// PolicyDryRun collects, scores and decides as usual but only records the remediations
+	// that would have been performed, as events, metrics and status entries.
+	// Escalation ladder steps are not persisted, so a node is reported for the step it would
+	// first be escalated to. Remediations that approval or the drain limits would hold back
+	// are reported as Wait entries; dry runs request no approval and hold no drain slot.
+	PolicyDryRun PolicyMode = "DryRun"
+	// PolicyDisabled ignores the covered nodes. They are not handed to other policies.
+	PolicyDisabled PolicyMode = "Disabled"
+)
+
+// +kubebuilder:validation:XValidation:rule="!has(self.healthyScore) || self.healthyScore <= self.unhealthyScore",message="healthyScore must not exceed unhealthyScore"
 type Thresholds struct {
 	// UnhealthyScore is the health score (0.0 - 1.0) above which a node is considered unhealthy.
 	// +kubebuilder:validation:Minimum=0.0
 	// +kubebuilder:validation:Maximum=1.0
 	UnhealthyScore float64 `json:"unhealthyScore"`
 
+	// HealthyScore is the health score below which an unhealthy node is considered recovering.
+	// Keeping it below UnhealthyScore stops a node whose score hovers around the threshold from
+	// flapping between healthy and unhealthy. Defaults to UnhealthyScore.
+	// +optional
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	HealthyScore float64 `json:"healthyScore,omitempty"`
+
+	// RecoveryWindow is how long a recovering node's score must stay below HealthyScore before
+	// it is healthy again. Defaults to EvaluationWindow.
+	// +optional
+	RecoveryWindow metav1.Duration `json:"recoveryWindow,omitempty"`
+
 	// EvaluationWindow is the duration for which the score must persist before action.
 	// +kubebuilder:default="5m"
 	EvaluationWindow metav1.Duration `json:"evaluationWindow,omitempty"`
+
+	// UnhealthyFraction is the share of scores within EvaluationWindow that must be at or above
+	// UnhealthyScore before action. Defaults to 1.0, i.e. the score must stay above the threshold
+	// for the whole window.
+	// +optional
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	UnhealthyFraction float64 `json:"unhealthyFraction,omitempty"`
+
+	// CriticalScore is the health score at or above which a node is remediated outside the
+	// maintenance windows. When unset the windows are never overridden.
+	// +optional
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	CriticalScore float64 `json:"criticalScore,omitempty"`
 }
 
 type Remediation struct {
@@ -41,6 +108,96 @@ type Remediation struct {
 	// Cooldown is the minimum time between remediations on the same node/pool.
 	// +kubebuilder:default="30m"
 	Cooldown metav1.Duration `json:"cooldown,omitempty"`
+
+	// Ladder is an escalation ladder of increasingly disruptive steps. A node climbs to the highest
+	// step whose conditions its score meets and steps back down once its score recovers.
+	// When empty an unhealthy node is cordoned and drained.
+	// +optional
+	Ladder []RemediationStep `json:"ladder,omitempty"`
+
+	// Windows are the maintenance windows within which remediation may evict pods from nodes.
+	// Tainting and cordoning are allowed at any time. When empty there is no restriction.
+	// +optional
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// Approval is Automatic to remediate right away, or Required to wait until a user approves
+	// the NodeRemediation requested for the node. Only remediations that evict pods need approval.
+	// +kubebuilder:default=Automatic
+	// +optional
+	Approval ApprovalMode `json:"approval,omitempty"`
+
+	// ApprovalTTL is how long a NodeRemediation waits for approval before it expires, and how long
+	// an approved one may wait for the policy's limits before the approval expires.
+	// +kubebuilder:default="1h"
+	// +optional
+	ApprovalTTL metav1.Duration `json:"approvalTTL,omitempty"`
+}
+
+// ApprovalMode decides whether remediations need a user's approval.
+// +kubebuilder:validation:Enum=Automatic;Required
+type ApprovalMode string
+
+const (
+	// ApprovalAutomatic remediates without approval. It is the default.
+	ApprovalAutomatic ApprovalMode = "Automatic"
+	// ApprovalRequired waits for a user to approve each remediation.
+	ApprovalRequired ApprovalMode = "Required"
+)
+
+// MaintenanceWindow is a recurring time range on selected weekdays. A window whose end is not
+// after its start runs past midnight into the next day; equal start and end cover the whole day.
+type MaintenanceWindow struct {
+	// Days are the weekdays the window starts on. When empty it starts every day.
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+
+	// Start is the local time of day the window opens, as HH:MM.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the local time of day the window closes, as HH:MM.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// TimeZone is the IANA time zone of Start and End, e.g. Europe/Berlin. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Weekday is a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// RemediationAction is a step of the escalation ladder.
+// +kubebuilder:validation:Enum=Taint;Cordon;Drain;Reboot;Replace
+type RemediationAction string
+
+const (
+	// RemediationTaint adds a PreferNoSchedule taint so new pods avoid the node.
+	RemediationTaint RemediationAction = "Taint"
+	// RemediationCordon marks the node unschedulable.
+	RemediationCordon RemediationAction = "Cordon"
+	// RemediationDrain cordons the node and evicts its pods.
+	RemediationDrain RemediationAction = "Drain"
+	// RemediationReboot drains the node and reboots its instance through the cloud provider.
+	RemediationReboot RemediationAction = "Reboot"
+	// RemediationReplace drains the node and replaces its instance through the cloud provider.
+	RemediationReplace RemediationAction = "Replace"
+)
+
+type RemediationStep struct {
+	// Action is the remediation applied on this step.
+	Action RemediationAction `json:"action"`
+
+	// MinScore is the health score at or above which the node is escalated to this step.
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	MinScore float64 `json:"minScore"`
+
+	// After, if set, only escalates to this step once the node spent this long on the previous
+	// step, e.g. to replace a node that is still bad 30 minutes after it was drained.
+	// +optional
+	After metav1.Duration `json:"after,omitempty"`
 }
 
 type Limits struct {
@@ -48,6 +205,93 @@ type Limits struct {
 	// +kubebuilder:default=1
 	// +kubebuilder:validation:Minimum=1
 	MaxConcurrentDrains int `json:"maxConcurrentDrains,omitempty"`
+
+	// MaxUnavailable is the maximum number of nodes of the pool, absolute or as a percentage of the
+	// pool size, that may be unavailable (cordoned, NotReady or missing) after a remediation starts.
+	// Percentages are rounded up. When unset only MaxConcurrentDrains limits remediation.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MinHealthyNodes is the number of healthy nodes the pool must keep. A remediation that would
+	// leave fewer healthy nodes waits.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinHealthyNodes int `json:"minHealthyNodes,omitempty"`
+
+	// SuppressCorrelated holds back remediation of nodes whose degradation correlates with
+	// other unhealthy nodes, as replacing them one by one rarely fixes a shared cause.
+	// +optional
+	SuppressCorrelated bool `json:"suppressCorrelated,omitempty"`
+}
+
+type Scoring struct {
+	// Weights sets the relative importance of each signal, keyed by metric name, e.g.
+	// {"disk_io_wait": 0.5} for IO-bound database pools. Weights are normalized to sum to 1.0.
+	// When empty the controller's default weights are used.
+	// +optional
+	Weights map[string]float64 `json:"weights,omitempty"`
+
+	// Normalization maps raw signal values to 0.0 - 1.0, per metric.
+	// Metrics without an entry must already be reported between 0.0 and 1.0.
+	// +optional
+	// +listType=map
+	// +listMapKey=metric
+	Normalization []MetricNormalization `json:"normalization,omitempty"`
+}
+
+// NormalizationType is the shape of the mapping from raw values to 0.0 - 1.0.
+// +kubebuilder:validation:Enum=Linear;Log;Logistic;Step
+type NormalizationType string
+
+const (
+	// NormalizationLinear maps floor to 0.0 and ceiling to 1.0 linearly.
+	NormalizationLinear NormalizationType = "Linear"
+	// NormalizationLog maps floor to 0.0 and ceiling to 1.0 on a logarithmic scale.
+	NormalizationLog NormalizationType = "Log"
+	// NormalizationLogistic is an S-curve centered on midpoint.
+	NormalizationLogistic NormalizationType = "Logistic"
+	// NormalizationStep maps raw values to fixed scores at thresholds.
+	NormalizationStep NormalizationType = "Step"
+)
+
+type MetricNormalization struct {
+	// Metric is the signal name, e.g. disk_io_wait.
+	Metric string `json:"metric"`
+
+	// Type selects the mapping.
+	Type NormalizationType `json:"type"`
+
+	// Floor is the raw value mapped to 0.0 by Linear and Log. Log requires a positive floor.
+	// +optional
+	Floor float64 `json:"floor,omitempty"`
+
+	// Ceiling is the raw value mapped to 1.0 by Linear and Log.
+	// +optional
+	Ceiling float64 `json:"ceiling,omitempty"`
+
+	// Midpoint is the raw value mapped to 0.5 by Logistic.
+	// +optional
+	Midpoint float64 `json:"midpoint,omitempty"`
+
+	// Steepness controls how sharply Logistic rises around the midpoint.
+	// +optional
+	Steepness float64 `json:"steepness,omitempty"`
+
+	// Steps are the thresholds used by Step. A raw value gets the score of the highest
+	// threshold it reaches, or 0.0 below all of them.
+	// +optional
+	Steps []NormalizationThreshold `json:"steps,omitempty"`
+}
+
+type NormalizationThreshold struct {
+	// Threshold is the raw value at which the step starts.
+	Threshold float64 `json:"threshold"`
+
+	// Score is the normalized value from the threshold on.
+	// +kubebuilder:validation:Minimum=0.0
+	// +kubebuilder:validation:Maximum=1.0
+	Score float64 `json:"score"`
 }
 
 // NodeHealingPolicyStatus defines the observed state of NodeHealingPolicy
@@ -59,9 +303,194 @@ type NodeHealingPolicyStatus struct {
 	// LastEvaluated is the timestamp of the last health check.
 	// +optional
 	LastEvaluated *metav1.Time `json:"lastEvaluated,omitempty"`
+
+	// LastRemediation is the most recent remediation of any node covered by the policy.
+	// It drives the pool-level cooldown.
+	// +optional
+	LastRemediation *RemediationRecord `json:"lastRemediation,omitempty"`
+
+	// Replacements tracks the nodes whose instances are being replaced, until a new node takes
+	// each one's place. A finished replacement keeps its claim on the new node for a while so no
+	// other replacement counts the same node.
+	// +optional
+	// +listType=map
+	// +listMapKey=node
+	Replacements []ReplacementRecord `json:"replacements,omitempty"`
+
+	// DryRunRemediations lists the most recent remediations a DryRun policy would have performed,
+	// oldest first.
+	// +optional
+	DryRunRemediations []RemediationRecord `json:"dryRunRemediations,omitempty"`
+
+	// Correlations lists attributes over-represented among the currently unhealthy nodes,
+	// hinting at a shared cause such as a zone outage or a bad kernel version.
+	// +optional
+	Correlations []CorrelationFinding `json:"correlations,omitempty"`
+
+	// Conditions describe the current state of the policy.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ReplacementRecord is the replacement of a node's instance by the cloud provider.
+type ReplacementRecord struct {
+	// Node is the name of the replaced node.
+	Node string `json:"node"`
+
+	// Remediation is the node's remediation record, kept here as the node may be gone.
+	Remediation RemediationRecord `json:"remediation"`
+
+	// Replacement is the new node claimed as the replacement, once one is Ready.
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// CorrelationFinding is an attribute value shared by more unhealthy nodes than chance explains.
+type CorrelationFinding struct {
+	// Attribute is the shared property, e.g. topology.kubernetes.io/zone or nodeInfo.kernelVersion.
+	Attribute string `json:"attribute"`
+
+	// Value is the shared value of the attribute.
+	Value string `json:"value"`
+
+	// UnhealthyNodes is the number of unhealthy nodes with the value.
+	UnhealthyNodes int `json:"unhealthyNodes"`
+
+	// Nodes is the number of nodes with the value.
+	Nodes int `json:"nodes"`
+
+	// TotalUnhealthy is the number of unhealthy nodes covered by the policy.
+	TotalUnhealthy int `json:"totalUnhealthy"`
+
+	// Examples lists some of the unhealthy nodes with the value.
+	// +optional
+	Examples []string `json:"examples,omitempty"`
 }
 
+// NodeHealthState is where a node stands between the unhealthy and healthy thresholds.
+type NodeHealthState string
+
+const (
+	// NodeHealthy nodes score below UnhealthyScore.
+	NodeHealthy NodeHealthState = "Healthy"
+	// NodeDegraded nodes reached UnhealthyScore but not yet for the evaluation window.
+	NodeDegraded NodeHealthState = "Degraded"
+	// NodeUnhealthy nodes stayed at or above UnhealthyScore for the evaluation window and have
+	// not dropped below HealthyScore since.
+	NodeUnhealthy NodeHealthState = "Unhealthy"
+	// NodeRecovering nodes were unhealthy and score below HealthyScore, but not yet for the
+	// recovery window.
+	NodeRecovering NodeHealthState = "Recovering"
+)
+
+const (
+	// LabelHealthState is the node label holding the node's NodeHealthState.
+	LabelHealthState = "infra.example.com/health-state"
+
+	// NodeConditionHealthScoreDegraded is the node condition type reporting the node's
+	// NodeHealthState as its reason. It is True unless the node is healthy.
+	NodeConditionHealthScoreDegraded = "HealthScoreDegraded"
+)
+
+// RemediationOutcome is the result of a remediation.
+type RemediationOutcome string
+
+const (
+	RemediationInProgress RemediationOutcome = "InProgress"
+	RemediationSucceeded  RemediationOutcome = "Succeeded"
+	RemediationFailed     RemediationOutcome = "Failed"
+	// RemediationDryRun marks a remediation a DryRun policy would have performed.
+	RemediationDryRun RemediationOutcome = "DryRun"
+)
+
+// RemediationRecord describes a remediation performed on a node.
+type RemediationRecord struct {
+	// Node is the name of the remediated node.
+	Node string `json:"node"`
+
+	// Action is the remediation performed, e.g. CordonAndDrain.
+	Action string `json:"action"`
+
+	// Outcome is the result of the remediation.
+	Outcome RemediationOutcome `json:"outcome"`
+
+	// Time is when the remediation started.
+	Time metav1.Time `json:"time"`
+
+	// Message explains the outcome, e.g. the reason for a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Progress describes how far the workflow got in its phase, e.g. the pods a drain is still
+	// waiting for and the PodDisruptionBudgets blocking them.
+	// +optional
+	Progress string `json:"progress,omitempty"`
+
+	// Phase is the step of the remediation workflow the node is in.
+	// +optional
+	Phase RemediationPhase `json:"phase,omitempty"`
+
+	// PhaseTime is when the node entered Phase.
+	// +optional
+	PhaseTime *metav1.Time `json:"phaseTime,omitempty"`
+
+	// EvictionRetries counts the consecutive drain passes whose evictions were refused, e.g. by
+	// a PodDisruptionBudget. The delay before the next pass doubles with each of them.
+	// +optional
+	EvictionRetries int `json:"evictionRetries,omitempty"`
+
+	// EvictionRetryTime is when the drain next retries the refused evictions.
+	// +optional
+	EvictionRetryTime *metav1.Time `json:"evictionRetryTime,omitempty"`
+
+	// BootID is the node's boot ID when a reboot was requested, to tell when it rebooted.
+	// +optional
+	BootID string `json:"bootID,omitempty"`
+
+	// Approval is the NodeRemediation that approved the remediation, if the policy requires approval.
+	// +optional
+	Approval string `json:"approval,omitempty"`
+}
+
+// RemediationPhase is a step of the remediation workflow. A node is cordoned, drained and, for
+// the Reboot and Replace actions, rebooted or replaced, persisting each step so the workflow
+// resumes where it left off after a controller restart.
+type RemediationPhase string
+
+const (
+	PhasePending   RemediationPhase = "Pending"
+	PhaseCordoned  RemediationPhase = "Cordoned"
+	PhaseDraining  RemediationPhase = "Draining"
+	PhaseDrained   RemediationPhase = "Drained"
+	PhaseRebooting RemediationPhase = "Rebooting"
+	PhaseRebooted  RemediationPhase = "Rebooted"
+	PhaseReplacing RemediationPhase = "Replacing"
+	PhaseReplaced  RemediationPhase = "Replaced"
+	PhaseFailed    RemediationPhase = "Failed"
+)
+
+const (
+	// ConditionTelemetryHealthy reports whether the signal upstream can be trusted.
+	// While it is False no remediation is performed.
+	ConditionTelemetryHealthy = "TelemetryHealthy"
+
+	// ConditionConfigValid reports whether the policy spec could be applied.
+	// Nodes covered by an invalid policy are not evaluated.
+	ConditionConfigValid = "ConfigValid"
+
+	// ConditionConflict reports whether the policy's selector overlaps with other policies.
+	// Overlapping nodes are covered by the policy with the highest precedence only.
+	ConditionConflict = "Conflict"
+
+	// ConditionSystemicFailure reports whether remediation is halted because too many nodes
+	// of the pool or the cluster look unhealthy at once.
+	ConditionSystemicFailure = "SystemicFailure"
+)
+
 // +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
 // +kubebuilder:subresource:status
 
 // NodeHealingPolicy is the Schema for the nodehealingpolicies API
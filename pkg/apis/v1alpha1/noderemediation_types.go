@@ -0,0 +1,109 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeRemediationSpec describes a remediation proposed for a node.
+type NodeRemediationSpec struct {
+	// NodeName is the node to remediate.
+	NodeName string `json:"nodeName"`
+
+	// PolicyName is the NodeHealingPolicy that proposed the remediation.
+	PolicyName string `json:"policyName"`
+
+	// Action is the proposed remediation, e.g. CordonAndDrain or a ladder step such as Replace.
+	Action string `json:"action"`
+
+	// Reason explains why the remediation was proposed.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Score is the node's health score when the remediation was proposed.
+	Score float64 `json:"score"`
+
+	// ScoreBreakdown is each metric's contribution to Score.
+	// +optional
+	ScoreBreakdown map[string]float64 `json:"scoreBreakdown,omitempty"`
+
+	// Approved is set by a user to let the remediation proceed. Setting the
+	// infra.example.com/approved annotation to "true" has the same effect.
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+}
+
+// NodeRemediationPhase is the lifecycle phase of a NodeRemediation.
+type NodeRemediationPhase string
+
+const (
+	// NodeRemediationPendingApproval remediations wait for a user's approval.
+	NodeRemediationPendingApproval NodeRemediationPhase = "PendingApproval"
+	// NodeRemediationApproved remediations were approved and start once the policy's limits allow,
+	// unless the approval TTL passes first.
+	NodeRemediationApproved NodeRemediationPhase = "Approved"
+	// NodeRemediationInProgress remediations were started; the approval is used up.
+	NodeRemediationInProgress NodeRemediationPhase = "InProgress"
+	// NodeRemediationExpired remediations were not approved, or not started once approved, within
+	// the policy's approval TTL.
+	NodeRemediationExpired NodeRemediationPhase = "Expired"
+	// NodeRemediationSuperseded remediations were replaced by a proposal for a different action,
+	// or withdrawn because the node recovered.
+	NodeRemediationSuperseded NodeRemediationPhase = "Superseded"
+	// NodeRemediationSucceeded remediations were performed.
+	NodeRemediationSucceeded NodeRemediationPhase = "Succeeded"
+	// NodeRemediationFailed remediations were attempted and failed.
+	NodeRemediationFailed NodeRemediationPhase = "Failed"
+)
+
+const (
+	// AnnotationApproved approves a NodeRemediation when set to "true".
+	AnnotationApproved = "infra.example.com/approved"
+
+	// LabelNodeName is the NodeRemediation label holding spec.nodeName, for listing by node.
+	LabelNodeName = "infra.example.com/node"
+)
+
+// NodeRemediationStatus defines the observed state of NodeRemediation.
+type NodeRemediationStatus struct {
+	// Phase is where the remediation is in its lifecycle.
+	// +optional
+	Phase NodeRemediationPhase `json:"phase,omitempty"`
+
+	// ExpiresAt is when the remediation expires unless it is approved or, once approved, started.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Message explains the phase, e.g. the reason for a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+// +kubebuilder:printcolumn:name="Action",type=string,JSONPath=`.spec.action`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// NodeRemediation is a remediation proposed for a node by a policy that requires approval.
+type NodeRemediation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeRemediationSpec   `json:"spec,omitempty"`
+	Status NodeRemediationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeRemediationList contains a list of NodeRemediation
+type NodeRemediationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeRemediation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeRemediation{}, &NodeRemediationList{})
+}
@@ -4,7 +4,9 @@
 package v1alpha1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -77,8 +79,77 @@ func (in *NodeHealingPolicySpec) DeepCopyInto(out *NodeHealingPolicySpec) {
 		}
 	}
 	out.Thresholds = in.Thresholds
-	out.Remediation = in.Remediation
-	out.Limits = in.Limits
+	in.Remediation.DeepCopyInto(&out.Remediation)
+	in.Limits.DeepCopyInto(&out.Limits)
+	in.Scoring.DeepCopyInto(&out.Scoring)
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Remediation) DeepCopyInto(out *Remediation) {
+	*out = *in
+	out.DrainTimeout = in.DrainTimeout
+	out.Cooldown = in.Cooldown
+	if in.Ladder != nil {
+		in, out := &in.Ladder, &out.Ladder
+		*out = make([]RemediationStep, len(*in))
+		copy(*out, *in)
+	}
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Limits) DeepCopyInto(out *Limits) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Scoring) DeepCopyInto(out *Scoring) {
+	*out = *in
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Normalization != nil {
+		in, out := &in.Normalization, &out.Normalization
+		*out = make([]MetricNormalization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricNormalization) DeepCopyInto(out *MetricNormalization) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]NormalizationThreshold, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -93,4 +164,167 @@ func (in *NodeHealingPolicyStatus) DeepCopyInto(out *NodeHealingPolicyStatus) {
 		in, out := &in.LastEvaluated, &out.LastEvaluated
 		*out = (*in).DeepCopy()
 	}
+	if in.LastRemediation != nil {
+		in, out := &in.LastRemediation, &out.LastRemediation
+		*out = new(RemediationRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replacements != nil {
+		in, out := &in.Replacements, &out.Replacements
+		*out = make([]ReplacementRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DryRunRemediations != nil {
+		in, out := &in.DryRunRemediations, &out.DryRunRemediations
+		*out = make([]RemediationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Correlations != nil {
+		in, out := &in.Correlations, &out.Correlations
+		*out = make([]CorrelationFinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRecord) DeepCopyInto(out *RemediationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.PhaseTime != nil {
+		in, out := &in.PhaseTime, &out.PhaseTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EvictionRetryTime != nil {
+		in, out := &in.EvictionRetryTime, &out.EvictionRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRecord.
+func (in *RemediationRecord) DeepCopy() *RemediationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplacementRecord) DeepCopyInto(out *ReplacementRecord) {
+	*out = *in
+	in.Remediation.DeepCopyInto(&out.Remediation)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplacementRecord.
+func (in *ReplacementRecord) DeepCopy() *ReplacementRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplacementRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CorrelationFinding) DeepCopyInto(out *CorrelationFinding) {
+	*out = *in
+	if in.Examples != nil {
+		in, out := &in.Examples, &out.Examples
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediation) DeepCopyInto(out *NodeRemediation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediation.
+func (in *NodeRemediation) DeepCopy() *NodeRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRemediation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationList) DeepCopyInto(out *NodeRemediationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeRemediation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeRemediationList.
+func (in *NodeRemediationList) DeepCopy() *NodeRemediationList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeRemediationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeRemediationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationSpec) DeepCopyInto(out *NodeRemediationSpec) {
+	*out = *in
+	if in.ScoreBreakdown != nil {
+		in, out := &in.ScoreBreakdown, &out.ScoreBreakdown
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeRemediationStatus) DeepCopyInto(out *NodeRemediationStatus) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
 }
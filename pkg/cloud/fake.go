@@ -0,0 +1,64 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeProvider is an in-memory Provider for tests. It records the instances it was asked to
+// reboot or replace and serves pool sizes from a map.
+type FakeProvider struct {
+	mu sync.Mutex
+
+	// PoolSizes maps pool IDs to their size. Unknown pools are an error.
+	PoolSizes map[string]int
+
+	// Err, if set, is returned by every call.
+	Err error
+
+	// Replaced and Rebooted are the instance IDs passed to ReplaceNode and RebootNode, in order.
+	Replaced []string
+	Rebooted []string
+}
+
+// NewFakeProvider returns a FakeProvider without pools.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{PoolSizes: map[string]int{}}
+}
+
+// ReplaceNode records the instance as replaced.
+func (f *FakeProvider) ReplaceNode(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Replaced = append(f.Replaced, nodeID)
+	return nil
+}
+
+// RebootNode records the instance as rebooted.
+func (f *FakeProvider) RebootNode(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.Rebooted = append(f.Rebooted, nodeID)
+	return nil
+}
+
+// GetNodePoolSize returns the configured size of the pool.
+func (f *FakeProvider) GetNodePoolSize(ctx context.Context, poolID string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	size, ok := f.PoolSizes[poolID]
+	if !ok {
+		return 0, fmt.Errorf("node pool %s not found", poolID)
+	}
+	return size, nil
+}
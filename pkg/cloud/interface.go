@@ -10,6 +10,9 @@ type Provider interface {
 	// ReplaceNode triggers the cloud provider to replace the instance.
 	ReplaceNode(ctx context.Context, nodeID string) error
 
+	// RebootNode reboots the instance in place.
+	RebootNode(ctx context.Context, nodeID string) error
+
 	// GetNodePoolSize returns the current size and health of the node pool.
 	GetNodePoolSize(ctx context.Context, poolID string) (int, error)
 }
@@ -0,0 +1,22 @@
+package cloud
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstanceID extracts the cloud instance ID from a node's spec.providerID, which has the form
+// <provider>://<provider-specific path> with the instance ID as its last segment, e.g.
+// aws:///us-east-1a/i-0abc, gce://project/zone/instance or
+// azure:///subscriptions/.../virtualMachines/instance.
+func InstanceID(providerID string) (string, error) {
+	provider, path, ok := strings.Cut(providerID, "://")
+	if !ok || provider == "" {
+		return "", fmt.Errorf("invalid provider ID %q: expected <provider>://<instance path>", providerID)
+	}
+	id := path[strings.LastIndex(path, "/")+1:]
+	if id == "" {
+		return "", fmt.Errorf("invalid provider ID %q: no instance ID", providerID)
+	}
+	return id, nil
+}
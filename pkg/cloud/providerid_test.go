@@ -0,0 +1,31 @@
+package cloud
+
+import "testing"
+
+func TestInstanceID(t *testing.T) {
+	tests := []struct {
+		name       string
+		providerID string
+		want       string
+		wantErr    bool
+	}{
+		{name: "aws", providerID: "aws:///us-east-1a/i-0123456789abcdef0", want: "i-0123456789abcdef0"},
+		{name: "gce", providerID: "gce://my-project/europe-west1-b/pool-a-x7k2", want: "pool-a-x7k2"},
+		{name: "azure", providerID: "azure:///subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1", want: "vm-1"},
+		{name: "no path", providerID: "kind://node-1", want: "node-1"},
+		{name: "empty", providerID: "", wantErr: true},
+		{name: "no scheme", providerID: "i-0123456789abcdef0", wantErr: true},
+		{name: "no instance", providerID: "aws:///us-east-1a/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InstanceID(tt.providerID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InstanceID(%q) error = %v, wantErr %v", tt.providerID, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InstanceID(%q) = %q, want %q", tt.providerID, got, tt.want)
+			}
+		})
+	}
+}
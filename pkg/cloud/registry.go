@@ -0,0 +1,54 @@
+package cloud
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory creates a Provider from its provider-specific configuration, e.g. a URL or the path
+// of a configuration file.
+type Factory func(config string) (Provider, error)
+
+var (
+	registryMu sync.Mutex
+	factories  = map[string]Factory{}
+)
+
+// Register makes a provider available to New under the given name. It panics if the name is
+// already taken, as two providers registering under one name is a programming error.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("cloud provider %s registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// New creates the provider registered under name.
+func New(name, config string) (Provider, error) {
+	registryMu.Lock()
+	factory, ok := factories[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown cloud provider %q, registered providers are %v", name, Names())
+	}
+	p, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud provider %s: %w", name, err)
+	}
+	return p, nil
+}
+
+// Names returns the names of the registered providers, sorted.
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,102 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("webhook", func(config string) (Provider, error) {
+		return NewWebhookProvider(config)
+	})
+}
+
+// webhookTimeout bounds each call to the webhook.
+const webhookTimeout = 30 * time.Second
+
+// WebhookProvider delegates node operations to an HTTP service, for clouds or in-house tooling
+// without a built-in provider. Relative to the base URL it calls
+//
+//	POST instances/{instanceID}/replace
+//	POST instances/{instanceID}/reboot
+//	GET  pools/{poolID}  → {"size": <number of instances>}
+//
+// Any status other than 2xx is an error. Replacing and rebooting only have to be started by the
+// time the call returns.
+type WebhookProvider struct {
+	base   *url.URL
+	client *http.Client
+}
+
+// NewWebhookProvider creates a provider calling the webhook at baseURL.
+func NewWebhookProvider(baseURL string) (*WebhookProvider, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL %q: scheme must be http or https", baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &WebhookProvider{base: u, client: &http.Client{Timeout: webhookTimeout}}, nil
+}
+
+// ReplaceNode asks the webhook to replace the instance.
+func (p *WebhookProvider) ReplaceNode(ctx context.Context, nodeID string) error {
+	return p.call(ctx, http.MethodPost, "instances/"+url.PathEscape(nodeID)+"/replace", nil)
+}
+
+// RebootNode asks the webhook to reboot the instance.
+func (p *WebhookProvider) RebootNode(ctx context.Context, nodeID string) error {
+	return p.call(ctx, http.MethodPost, "instances/"+url.PathEscape(nodeID)+"/reboot", nil)
+}
+
+// GetNodePoolSize asks the webhook for the number of instances in the pool.
+func (p *WebhookProvider) GetNodePoolSize(ctx context.Context, poolID string) (int, error) {
+	var pool struct {
+		Size *int `json:"size"`
+	}
+	if err := p.call(ctx, http.MethodGet, "pools/"+url.PathEscape(poolID), &pool); err != nil {
+		return 0, err
+	}
+	if pool.Size == nil {
+		return 0, fmt.Errorf("webhook response for pool %s has no size", poolID)
+	}
+	return *pool.Size, nil
+}
+
+// call sends the request and decodes the JSON response into out, if set.
+func (p *WebhookProvider) call(ctx context.Context, method, path string, out interface{}) error {
+	u := p.base.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s %s returned %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode webhook response: %w", err)
+	}
+	return nil
+}
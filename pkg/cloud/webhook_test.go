@@ -0,0 +1,73 @@
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookProvider(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/pools/pool-a":
+			_, _ = w.Write([]byte(`{"size": 5}`))
+		case "/api/pools/empty":
+			_, _ = w.Write([]byte(`{}`))
+		case "/api/instances/i-0abc/replace", "/api/instances/i-0abc/reboot":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "no such instance", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, err := New("webhook", server.URL+"/api")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.TODO()
+
+	if size, err := p.GetNodePoolSize(ctx, "pool-a"); err != nil || size != 5 {
+		t.Errorf("GetNodePoolSize() = %d, %v, want 5", size, err)
+	}
+	if _, err := p.GetNodePoolSize(ctx, "empty"); err == nil {
+		t.Errorf("GetNodePoolSize() without a size succeeded")
+	}
+	if err := p.ReplaceNode(ctx, "i-0abc"); err != nil {
+		t.Errorf("ReplaceNode() error = %v", err)
+	}
+	if err := p.RebootNode(ctx, "i-0abc"); err != nil {
+		t.Errorf("RebootNode() error = %v", err)
+	}
+	if err := p.ReplaceNode(ctx, "i-missing"); err == nil {
+		t.Errorf("ReplaceNode() of a missing instance succeeded")
+	}
+
+	want := []string{
+		"GET /api/pools/pool-a",
+		"GET /api/pools/empty",
+		"POST /api/instances/i-0abc/replace",
+		"POST /api/instances/i-0abc/reboot",
+		"POST /api/instances/i-missing/replace",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %s, want %s", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("no-such-cloud", ""); err == nil {
+		t.Errorf("New() of an unregistered provider succeeded")
+	}
+	if _, err := New("webhook", "ftp://example.com"); err == nil {
+		t.Errorf("New() with an invalid webhook URL succeeded")
+	}
+}
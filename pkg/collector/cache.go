@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CachedCollector serves per-node signals from a shared, periodically refreshed
+// snapshot of the whole node pool. Concurrent callers that miss the cache wait for
+// a single in-flight refresh instead of each issuing their own queries.
+type CachedCollector struct {
+	source    BatchSignalCollector
+	listNodes NodeLister
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	snapshot  map[string]Signals
+	fetchedAt time.Time
+	inflight  *refresh
+}
+
+type refresh struct {
+	done     chan struct{}
+	snapshot map[string]Signals
+	err      error
+}
+
+// NewCachedCollector creates a collector that refreshes signals for all nodes returned
+// by listNodes at most once per ttl.
+func NewCachedCollector(source BatchSignalCollector, listNodes NodeLister, ttl time.Duration) *CachedCollector {
+	return &CachedCollector{
+		source:    source,
+		listNodes: listNodes,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// ClientNodeLister lists all nodes known to the given reader.
+func ClientNodeLister(c client.Reader) NodeLister {
+	return func(ctx context.Context) ([]string, error) {
+		var nodes corev1.NodeList
+		if err := c.List(ctx, &nodes); err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		names := make([]string, 0, len(nodes.Items))
+		for _, node := range nodes.Items {
+			names = append(names, node.Name)
+		}
+		return names, nil
+	}
+}
+
+// CollectSignals returns the cached signals for a node, refreshing the pool snapshot when
+// it has expired or does not yet include the node. Like CollectPoolSignals it returns the
+// signals that did arrive together with a *PartialFailureError.
+func (c *CachedCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	result, err := c.CollectPoolSignals(ctx, []string{nodeName})
+	if result == nil {
+		return nil, err
+	}
+	return result[nodeName], err
+}
+
+// CollectPoolSignals returns the cached signals for the given nodes. If the source failed only
+// partially the signals it did return are passed on together with its *PartialFailureError;
+// they are not cached, so the next call retries the source.
+func (c *CachedCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	snapshot, err := c.get(ctx, nodeNames)
+	if err != nil && !(IsPartialFailure(err) && snapshot != nil) {
+		return nil, err
+	}
+	result := make(map[string]Signals, len(nodeNames))
+	for _, name := range nodeNames {
+		result[name] = copySignals(snapshot[name])
+	}
+	return result, err
+}
+
+// Invalidate drops the cached snapshot so the next call refreshes it.
+func (c *CachedCollector) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot = nil
+}
+
+// get returns a snapshot including the nodes. A refresh started for other nodes may not include
+// them, e.g. nodes that just joined; the caller then waits for a refresh of its own.
+func (c *CachedCollector) get(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	for {
+		c.mu.Lock()
+		if c.snapshot != nil && c.now().Sub(c.fetchedAt) < c.ttl && containsAll(c.snapshot, nodeNames) {
+			snapshot := c.snapshot
+			c.mu.Unlock()
+			return snapshot, nil
+		}
+
+		r := c.inflight
+		joined := r != nil
+		if !joined {
+			r = &refresh{done: make(chan struct{})}
+			c.inflight = r
+			go c.refresh(r, nodeNames)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if !joined || r.snapshot == nil || containsAll(r.snapshot, nodeNames) {
+			return r.snapshot, r.err
+		}
+	}
+}
+
+// refresh fetches a new snapshot. It runs detached from any single caller's context so
+// that one cancelled reconcile does not fail the refresh for everyone waiting on it.
+func (c *CachedCollector) refresh(r *refresh, requested []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	r.snapshot, r.err = c.fetch(ctx, requested)
+
+	c.mu.Lock()
+	if r.err == nil && r.snapshot != nil {
+		c.snapshot = r.snapshot
+		c.fetchedAt = c.now()
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(r.done)
+}
+
+func (c *CachedCollector) fetch(ctx context.Context, requested []string) (map[string]Signals, error) {
+	nodes, err := c.listNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Nodes that are not yet visible to the lister are still fetched for the caller.
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		seen[n] = true
+	}
+	for _, n := range requested {
+		if !seen[n] {
+			nodes = append(nodes, n)
+		}
+	}
+	return c.source.CollectPoolSignals(ctx, nodes)
+}
+
+func containsAll(snapshot map[string]Signals, nodeNames []string) bool {
+	for _, n := range nodeNames {
+		if _, ok := snapshot[n]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func copySignals(in Signals) Signals {
+	out := make(Signals, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+type countingBatchCollector struct {
+	calls int32
+	delay time.Duration
+}
+
+func (c *countingBatchCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	atomic.AddInt32(&c.calls, 1)
+	time.Sleep(c.delay)
+	result := make(map[string]Signals, len(nodeNames))
+	for _, n := range nodeNames {
+		result[n] = Signals{scorer.MetricDiskIOWait: {Value: 0.5}}
+	}
+	return result, nil
+}
+
+func staticLister(names ...string) NodeLister {
+	return func(ctx context.Context) ([]string, error) {
+		return names, nil
+	}
+}
+
+func TestCachedCollector_SharesRefresh(t *testing.T) {
+	source := &countingBatchCollector{delay: 20 * time.Millisecond}
+	c := NewCachedCollector(source, staticLister("a", "b", "c"), time.Minute)
+
+	var wg sync.WaitGroup
+	for _, node := range []string{"a", "b", "c", "a", "b", "c"} {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			signals, err := c.CollectSignals(context.TODO(), node)
+			if err != nil {
+				t.Errorf("CollectSignals(%s) error = %v", node, err)
+				return
+			}
+			if signals[scorer.MetricDiskIOWait].Value != 0.5 {
+				t.Errorf("CollectSignals(%s) = %v", node, signals)
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Errorf("expected a single pool query, got %d", calls)
+	}
+}
+
+func TestCachedCollector_TTL(t *testing.T) {
+	source := &countingBatchCollector{}
+	c := NewCachedCollector(source, staticLister("a"), time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	ctx := context.TODO()
+	if _, err := c.CollectSignals(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CollectSignals(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 1 {
+		t.Fatalf("expected cached result, got %d queries", calls)
+	}
+
+	// A node that joined after the last refresh forces a new scrape.
+	if _, err := c.CollectSignals(ctx, "new-node"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Fatalf("expected refresh for unknown node, got %d queries", calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := c.CollectSignals(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 3 {
+		t.Errorf("expected refresh after TTL, got %d queries", calls)
+	}
+}
+
+type partialBatchCollector struct {
+	calls int32
+}
+
+func (c *partialBatchCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	atomic.AddInt32(&c.calls, 1)
+	result := make(map[string]Signals, len(nodeNames))
+	for _, n := range nodeNames {
+		result[n] = Signals{scorer.MetricDiskIOWait: {Value: 0.5}}
+	}
+	return result, &PartialFailureError{Failed: map[string]error{"events": errors.New("unavailable")}}
+}
+
+func TestCachedCollector_PartialFailure(t *testing.T) {
+	source := &partialBatchCollector{}
+	c := NewCachedCollector(source, staticLister("a"), time.Minute)
+
+	ctx := context.TODO()
+	signals, err := c.CollectSignals(ctx, "a")
+	if !IsPartialFailure(err) {
+		t.Fatalf("CollectSignals() error = %v, want a partial failure", err)
+	}
+	if signals[scorer.MetricDiskIOWait].Value != 0.5 {
+		t.Errorf("CollectSignals() = %v, want the signals that arrived", signals)
+	}
+
+	// Partial results are not cached.
+	if _, err := c.CollectSignals(ctx, "a"); !IsPartialFailure(err) {
+		t.Fatalf("CollectSignals() error = %v, want a partial failure", err)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Errorf("expected a query per call, got %d", calls)
+	}
+}
+
+type gatedBatchCollector struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (c *gatedBatchCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	if atomic.AddInt32(&c.calls, 1) == 1 {
+		<-c.release
+	}
+	result := make(map[string]Signals, len(nodeNames))
+	for _, n := range nodeNames {
+		result[n] = Signals{scorer.MetricDiskIOWait: {Value: 0.5}}
+	}
+	return result, nil
+}
+
+func TestCachedCollector_JoinedRefreshMissesNode(t *testing.T) {
+	source := &gatedBatchCollector{release: make(chan struct{})}
+	c := NewCachedCollector(source, staticLister("a"), time.Minute)
+	ctx := context.TODO()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := c.CollectSignals(ctx, "a"); err != nil {
+			t.Errorf("CollectSignals(a) error = %v", err)
+		}
+	}()
+	for atomic.LoadInt32(&source.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// new-node joins the refresh started for a, which does not cover it.
+	result := make(chan Signals, 1)
+	go func() {
+		signals, err := c.CollectSignals(ctx, "new-node")
+		if err != nil {
+			t.Errorf("CollectSignals(new-node) error = %v", err)
+		}
+		result <- signals
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(source.release)
+	<-done
+
+	if signals := <-result; signals[scorer.MetricDiskIOWait].Value != 0.5 {
+		t.Errorf("CollectSignals(new-node) = %v, want its signals", signals)
+	}
+	if calls := atomic.LoadInt32(&source.calls); calls != 2 {
+		t.Errorf("expected a second query for new-node, got %d", calls)
+	}
+}
@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all calls through; the upstream is considered healthy.
+	CircuitClosed CircuitState = "Closed"
+	// CircuitOpen rejects all calls; the upstream is considered down.
+	CircuitOpen CircuitState = "Open"
+	// CircuitHalfOpen lets a single probe through to test whether the upstream recovered.
+	CircuitHalfOpen CircuitState = "HalfOpen"
+)
+
+// ErrCircuitOpen is returned while the circuit is open.
+var ErrCircuitOpen = errors.New("telemetry circuit is open")
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Name identifies the guarded upstream in metrics and messages.
+	Name string
+
+	// ConsecutiveFailures opens the circuit after this many failures in a row.
+	ConsecutiveFailures int
+
+	// ErrorRate opens the circuit when the share of failed calls in the window reaches it.
+	ErrorRate float64
+
+	// WindowSize is the number of recent calls the error rate is computed over.
+	WindowSize int
+
+	// MinCalls is the number of calls in the window before the error rate is considered.
+	MinCalls int
+
+	// SlowCall counts calls slower than this as failures. Zero disables the check.
+	SlowCall time.Duration
+
+	// OpenDuration is how long the circuit stays open before a probe is allowed.
+	OpenDuration time.Duration
+}
+
+// CircuitBreaker guards a collector's upstream. After repeated failures or slow calls it opens
+// and fails fast, so a dead Prometheus neither stalls every reconcile nor gets silently read as
+// healthy. Its state also tells the decision engine whether remediation can be trusted.
+type CircuitBreaker struct {
+	next NodeSignalCollector
+	cfg  CircuitBreakerConfig
+	now  func() time.Time
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	consecutive int
+	outcomes    []bool // ring buffer of recent calls, true means failure
+	cursor      int
+	probing     bool
+	lastErr     error
+}
+
+// NewCircuitBreaker wraps a collector with a circuit breaker.
+func NewCircuitBreaker(next NodeSignalCollector, cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.Name == "" {
+		cfg.Name = "telemetry"
+	}
+	if cfg.ConsecutiveFailures <= 0 {
+		cfg.ConsecutiveFailures = 5
+	}
+	if cfg.ErrorRate <= 0 {
+		cfg.ErrorRate = 0.5
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = 20
+	}
+	if cfg.MinCalls <= 0 {
+		cfg.MinCalls = 10
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = time.Minute
+	}
+	cb := &CircuitBreaker{
+		next:     next,
+		cfg:      cfg,
+		now:      time.Now,
+		state:    CircuitClosed,
+		outcomes: make([]bool, 0, cfg.WindowSize),
+	}
+	circuitState.WithLabelValues(cfg.Name).Set(circuitStateValue(CircuitClosed))
+	return cb
+}
+
+// CollectSignals calls the wrapped collector unless the circuit is open.
+func (cb *CircuitBreaker) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	var signals Signals
+	err := cb.call(func() error {
+		var err error
+		signals, err = cb.next.CollectSignals(ctx, nodeName)
+		return err
+	})
+	return signals, err
+}
+
+// CollectPoolSignals calls the wrapped collector for many nodes unless the circuit is open.
+func (cb *CircuitBreaker) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	var result map[string]Signals
+	err := cb.call(func() error {
+		var err error
+		result, err = collectPool(ctx, cb.next, nodeNames)
+		return err
+	})
+	return result, err
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance()
+	return cb.state
+}
+
+// TelemetryHealthy reports whether the upstream can be trusted. Only a closed circuit is healthy;
+// a half-open circuit has not yet proven the upstream recovered.
+func (cb *CircuitBreaker) TelemetryHealthy() (bool, string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance()
+	if cb.state == CircuitClosed {
+		return true, ""
+	}
+	if cb.lastErr != nil {
+		return false, fmt.Sprintf("%s circuit is %s: %v", cb.cfg.Name, cb.state, cb.lastErr)
+	}
+	return false, fmt.Sprintf("%s circuit is %s", cb.cfg.Name, cb.state)
+}
+
+func (cb *CircuitBreaker) call(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+
+	start := cb.now()
+	err := fn()
+	elapsed := cb.now().Sub(start)
+	collectDuration.WithLabelValues(cb.cfg.Name).Observe(elapsed.Seconds())
+
+	failed := err != nil && !IsPartialFailure(err)
+	if !failed && cb.cfg.SlowCall > 0 && elapsed > cb.cfg.SlowCall {
+		failed = true
+		err = fmt.Errorf("call took %s, slower than %s", elapsed, cb.cfg.SlowCall)
+	}
+	if failed {
+		collectFailures.WithLabelValues(cb.cfg.Name).Inc()
+	}
+	cb.record(failed, err)
+	return err
+}
+
+// allow decides whether a call may go through.
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.advance()
+	switch cb.state {
+	case CircuitOpen:
+		return fmt.Errorf("%s: %w", cb.cfg.Name, ErrCircuitOpen)
+	case CircuitHalfOpen:
+		if cb.probing {
+			return fmt.Errorf("%s: %w", cb.cfg.Name, ErrCircuitOpen)
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+// advance moves an open circuit to half-open once the open duration has passed.
+func (cb *CircuitBreaker) advance() {
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.cfg.OpenDuration {
+		cb.setState(CircuitHalfOpen)
+	}
+}
+
+func (cb *CircuitBreaker) record(failed bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitHalfOpen {
+		cb.probing = false
+		if failed {
+			cb.lastErr = err
+			cb.trip()
+		} else {
+			cb.reset()
+		}
+		return
+	}
+
+	if len(cb.outcomes) < cb.cfg.WindowSize {
+		cb.outcomes = append(cb.outcomes, failed)
+	} else {
+		cb.outcomes[cb.cursor] = failed
+		cb.cursor = (cb.cursor + 1) % cb.cfg.WindowSize
+	}
+
+	if !failed {
+		cb.consecutive = 0
+		return
+	}
+	cb.lastErr = err
+	cb.consecutive++
+	if cb.consecutive >= cb.cfg.ConsecutiveFailures || cb.errorRateExceeded() {
+		cb.trip()
+	}
+}
+
+func (cb *CircuitBreaker) errorRateExceeded() bool {
+	if len(cb.outcomes) < cb.cfg.MinCalls {
+		return false
+	}
+	var failures int
+	for _, f := range cb.outcomes {
+		if f {
+			failures++
+		}
+	}
+	return float64(failures)/float64(len(cb.outcomes)) >= cb.cfg.ErrorRate
+}
+
+func (cb *CircuitBreaker) trip() {
+	cb.openedAt = cb.now()
+	cb.setState(CircuitOpen)
+}
+
+func (cb *CircuitBreaker) reset() {
+	cb.consecutive = 0
+	cb.outcomes = cb.outcomes[:0]
+	cb.cursor = 0
+	cb.lastErr = nil
+	cb.setState(CircuitClosed)
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	cb.state = state
+	circuitState.WithLabelValues(cb.cfg.Name).Set(circuitStateValue(state))
+}
+
+func circuitStateValue(state CircuitState) float64 {
+	switch state {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	upstream := &staticCollector{err: errors.New("connection refused")}
+	now := time.Now()
+	cb := NewCircuitBreaker(upstream, CircuitBreakerConfig{
+		Name:                "test",
+		ConsecutiveFailures: 3,
+		OpenDuration:        time.Minute,
+	})
+	cb.now = func() time.Time { return now }
+	ctx := context.TODO()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cb.CollectSignals(ctx, "worker-1"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: error = %v, want upstream error", i, err)
+		}
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("State() = %s, want %s", cb.State(), CircuitOpen)
+	}
+	if healthy, reason := cb.TelemetryHealthy(); healthy || reason == "" {
+		t.Errorf("TelemetryHealthy() = %v, %q, want unhealthy with reason", healthy, reason)
+	}
+
+	// While open, the upstream is not called at all.
+	upstream.err = nil
+	upstream.signals = Signals{scorer.MetricDiskIOWait: {Value: 0.1}}
+	if _, err := cb.CollectSignals(ctx, "worker-1"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+
+	// After the open duration a successful probe closes the circuit.
+	now = now.Add(2 * time.Minute)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("State() = %s, want %s", cb.State(), CircuitHalfOpen)
+	}
+	if _, err := cb.CollectSignals(ctx, "worker-1"); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if healthy, _ := cb.TelemetryHealthy(); !healthy {
+		t.Errorf("TelemetryHealthy() = false after successful probe")
+	}
+}
+
+func TestCircuitBreaker_ErrorRate(t *testing.T) {
+	upstream := &staticCollector{}
+	cb := NewCircuitBreaker(upstream, CircuitBreakerConfig{
+		ConsecutiveFailures: 100,
+		ErrorRate:           0.5,
+		WindowSize:          4,
+		MinCalls:            4,
+	})
+	ctx := context.TODO()
+
+	// Alternating failures never trip the consecutive counter but do reach the error rate.
+	for i := 0; i < 4; i++ {
+		if i%2 == 1 {
+			upstream.err = errors.New("timeout")
+		} else {
+			upstream.err = nil
+		}
+		_, _ = cb.CollectSignals(ctx, "worker-1")
+	}
+	if cb.State() != CircuitOpen {
+		t.Errorf("State() = %s, want %s", cb.State(), CircuitOpen)
+	}
+}
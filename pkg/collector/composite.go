@@ -0,0 +1,221 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// Source is a named collector taking part in a CompositeCollector.
+type Source struct {
+	Name      string
+	Collector NodeSignalCollector
+}
+
+// Result is the merged outcome of a composite collection for a single node.
+type Result struct {
+	// Signals are the merged signals. Each signal's Source is the name of the
+	// composite source that produced it.
+	Signals Signals
+	// Failed holds the error of every source that failed.
+	Failed map[string]error
+}
+
+// PartialFailureError reports that some, but not all, sources failed.
+// Signals collected from the remaining sources are still returned alongside it.
+type PartialFailureError struct {
+	Failed map[string]error
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("%d signal source(s) failed: %s", len(e.Failed), joinErrors(e.Failed))
+}
+
+// AllSourcesFailedError reports that no source produced any signals.
+type AllSourcesFailedError struct {
+	Failed map[string]error
+}
+
+func (e *AllSourcesFailedError) Error() string {
+	return fmt.Sprintf("all signal sources failed: %s", joinErrors(e.Failed))
+}
+
+// IsPartialFailure reports whether err only signals degraded, still usable, data.
+func IsPartialFailure(err error) bool {
+	var partial *PartialFailureError
+	return errors.As(err, &partial)
+}
+
+// CompositeCollector fans out to several collectors concurrently and merges their signals.
+// When more than one source reports the same metric, the per-metric precedence decides
+// which value wins; sources not listed there rank after listed ones in registration order.
+type CompositeCollector struct {
+	sources    []Source
+	precedence map[scorer.MetricName][]string
+}
+
+// NewCompositeCollector creates a collector merging the given sources.
+// precedence maps a metric to source names, highest priority first. It may be nil.
+func NewCompositeCollector(precedence map[scorer.MetricName][]string, sources ...Source) *CompositeCollector {
+	return &CompositeCollector{
+		sources:    sources,
+		precedence: precedence,
+	}
+}
+
+// CollectSignals returns the merged signals for the node. If some sources failed the merged
+// signals are returned together with a *PartialFailureError.
+func (c *CompositeCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	result, err := c.Collect(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Failed) > 0 {
+		return result.Signals, &PartialFailureError{Failed: result.Failed}
+	}
+	return result.Signals, nil
+}
+
+// Forget drops the node's state from every source that keeps any.
+func (c *CompositeCollector) Forget(nodeName string) {
+	for _, src := range c.sources {
+		if f, ok := src.Collector.(Forgetter); ok {
+			f.Forget(nodeName)
+		}
+	}
+}
+
+// Collect queries all sources for the node and merges their signals with provenance.
+// It only returns an error when every source failed.
+func (c *CompositeCollector) Collect(ctx context.Context, nodeName string) (Result, error) {
+	outcomes := make([]sourceOutcome, len(c.sources))
+	var wg sync.WaitGroup
+	for i, src := range c.sources {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			signals, err := src.Collector.CollectSignals(ctx, nodeName)
+			outcomes[i] = sourceOutcome{signals: signals, err: err}
+		}(i, src)
+	}
+	wg.Wait()
+
+	return c.merge(outcomes)
+}
+
+// CollectPoolSignals collects signals for many nodes. Sources implementing BatchSignalCollector
+// are queried once; the others are queried node by node. Like CollectSignals, a partial failure
+// returns the merged signals together with a *PartialFailureError.
+func (c *CompositeCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	pool := make([]map[string]Signals, len(c.sources))
+	errs := make([]error, len(c.sources))
+	var wg sync.WaitGroup
+	for i, src := range c.sources {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			pool[i], errs[i] = collectPool(ctx, src.Collector, nodeNames)
+		}(i, src)
+	}
+	wg.Wait()
+
+	result := make(map[string]Signals, len(nodeNames))
+	var failed map[string]error
+	for _, name := range nodeNames {
+		outcomes := make([]sourceOutcome, len(c.sources))
+		for i := range c.sources {
+			outcomes[i] = sourceOutcome{signals: pool[i][name], err: errs[i]}
+		}
+		merged, err := c.merge(outcomes)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = merged.Signals
+		failed = merged.Failed
+	}
+	if len(failed) > 0 {
+		return result, &PartialFailureError{Failed: failed}
+	}
+	return result, nil
+}
+
+func collectPool(ctx context.Context, c NodeSignalCollector, nodeNames []string) (map[string]Signals, error) {
+	if batch, ok := c.(BatchSignalCollector); ok {
+		return batch.CollectPoolSignals(ctx, nodeNames)
+	}
+	result := make(map[string]Signals, len(nodeNames))
+	for _, name := range nodeNames {
+		signals, err := c.CollectSignals(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = signals
+	}
+	return result, nil
+}
+
+type sourceOutcome struct {
+	signals Signals
+	err     error
+}
+
+// merge combines per-source outcomes, indexed like c.sources.
+func (c *CompositeCollector) merge(outcomes []sourceOutcome) (Result, error) {
+	result := Result{Signals: make(Signals)}
+	for i, o := range outcomes {
+		name := c.sources[i].Name
+		if o.err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]error)
+			}
+			result.Failed[name] = o.err
+			continue
+		}
+		for metric, sig := range o.signals {
+			if current, ok := result.Signals[metric]; ok && c.rank(metric, current.Source) <= c.rank(metric, name) {
+				continue
+			}
+			sig.Source = name
+			result.Signals[metric] = sig
+		}
+	}
+
+	if len(c.sources) > 0 && len(result.Failed) == len(c.sources) {
+		return Result{}, &AllSourcesFailedError{Failed: result.Failed}
+	}
+	return result, nil
+}
+
+// rank orders sources for a metric; lower wins.
+func (c *CompositeCollector) rank(metric scorer.MetricName, source string) int {
+	preferred := c.precedence[metric]
+	for i, name := range preferred {
+		if name == source {
+			return i
+		}
+	}
+	for i, src := range c.sources {
+		if src.Name == source {
+			return len(preferred) + i
+		}
+	}
+	return len(preferred) + len(c.sources)
+}
+
+func joinErrors(failed map[string]error) string {
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, failed[name]))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+type staticCollector struct {
+	signals Signals
+	err     error
+}
+
+func (s *staticCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	return s.signals, s.err
+}
+
+func TestCompositeCollector_Precedence(t *testing.T) {
+	c := NewCompositeCollector(
+		map[scorer.MetricName][]string{scorer.MetricKubeletErrors: {"events"}},
+		Source{Name: "prometheus", Collector: &staticCollector{signals: Signals{
+			scorer.MetricDiskIOWait:    {Value: 0.3},
+			scorer.MetricKubeletErrors: {Value: 0.1},
+		}}},
+		Source{Name: "events", Collector: &staticCollector{signals: Signals{
+			scorer.MetricKubeletErrors: {Value: 0.8},
+			scorer.MetricDiskIOWait:    {Value: 0.9},
+		}}},
+	)
+
+	got, err := c.Collect(context.TODO(), "worker-1")
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	want := Signals{
+		scorer.MetricDiskIOWait:    {Value: 0.3, Source: "prometheus"},
+		scorer.MetricKubeletErrors: {Value: 0.8, Source: "events"},
+	}
+	if !reflect.DeepEqual(got.Signals, want) {
+		t.Errorf("Signals = %v, want %v", got.Signals, want)
+	}
+}
+
+func TestCompositeCollector_Failures(t *testing.T) {
+	boom := errors.New("boom")
+	healthy := Source{Name: "conditions", Collector: &staticCollector{signals: Signals{scorer.MetricMemoryPressure: {Value: 1}}}}
+	broken := Source{Name: "prometheus", Collector: &staticCollector{err: boom}}
+
+	partial := NewCompositeCollector(nil, broken, healthy)
+	signals, err := partial.CollectSignals(context.TODO(), "worker-1")
+	if !IsPartialFailure(err) {
+		t.Fatalf("CollectSignals() error = %v, want partial failure", err)
+	}
+	if signals[scorer.MetricMemoryPressure].Value != 1 {
+		t.Errorf("CollectSignals() = %v, want signals from healthy source", signals)
+	}
+	if !errors.Is(err.(*PartialFailureError).Failed["prometheus"], boom) {
+		t.Errorf("Failed = %v, want prometheus: boom", err)
+	}
+
+	total := NewCompositeCollector(nil, broken)
+	_, err = total.CollectSignals(context.TODO(), "worker-1")
+	var all *AllSourcesFailedError
+	if !errors.As(err, &all) || IsPartialFailure(err) {
+		t.Errorf("CollectSignals() error = %v, want total failure", err)
+	}
+}
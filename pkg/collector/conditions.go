@@ -0,0 +1,240 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// DefaultPressureWeights is the contribution of each pressure condition to the memory_pressure signal.
+var DefaultPressureWeights = map[corev1.NodeConditionType]float64{
+	corev1.NodeMemoryPressure: 1.0,
+	corev1.NodePIDPressure:    0.5,
+	corev1.NodeDiskPressure:   0.5,
+}
+
+// DefaultFlapConditions are the conditions whose transitions count towards condition_flaps.
+var DefaultFlapConditions = []corev1.NodeConditionType{
+	corev1.NodeReady,
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+}
+
+// NodeConditionsConfig configures a NodeConditionsCollector.
+type NodeConditionsConfig struct {
+	// Window is the period over which condition transitions are counted when the collection
+	// does not ask for the policy's evaluation window with WithEvaluationWindow.
+	Window time.Duration
+
+	// MaxFlaps is the number of transitions within Window that maps to a flap signal of 1.0.
+	MaxFlaps int
+
+	// PressureWeights overrides DefaultPressureWeights.
+	PressureWeights map[corev1.NodeConditionType]float64
+
+	// FlapConditions overrides DefaultFlapConditions.
+	FlapConditions []corev1.NodeConditionType
+}
+
+// NodeConditionsCollector derives memory_pressure and condition_flaps from the node's status conditions.
+// It remembers the last seen transition of every tracked condition so flaps that happen between two
+// collections are still counted. Transitions are kept for the longest window asked for, so policies
+// with different evaluation windows each count over their own.
+type NodeConditionsCollector struct {
+	client          client.Reader
+	window          time.Duration
+	maxFlaps        int
+	pressureWeights map[corev1.NodeConditionType]float64
+	flapConditions  []corev1.NodeConditionType
+	now             func() time.Time
+
+	mu      sync.Mutex
+	history map[string]*conditionHistory
+	// retain is the longest window asked for so far.
+	retain time.Duration
+}
+
+type conditionHistory struct {
+	// lastTransition is the last seen LastTransitionTime per condition.
+	lastTransition map[corev1.NodeConditionType]time.Time
+	// transitions holds the time of every observed transition within the window.
+	transitions []time.Time
+}
+
+// NewNodeConditionsCollector creates a collector reading nodes from the given reader.
+func NewNodeConditionsCollector(c client.Reader, cfg NodeConditionsConfig) *NodeConditionsCollector {
+	if cfg.Window <= 0 {
+		cfg.Window = 5 * time.Minute
+	}
+	if cfg.MaxFlaps <= 0 {
+		cfg.MaxFlaps = 3
+	}
+	if cfg.PressureWeights == nil {
+		cfg.PressureWeights = DefaultPressureWeights
+	}
+	if cfg.FlapConditions == nil {
+		cfg.FlapConditions = DefaultFlapConditions
+	}
+	return &NodeConditionsCollector{
+		client:          c,
+		window:          cfg.Window,
+		maxFlaps:        cfg.MaxFlaps,
+		pressureWeights: cfg.PressureWeights,
+		flapConditions:  cfg.FlapConditions,
+		now:             time.Now,
+		history:         make(map[string]*conditionHistory),
+	}
+}
+
+// CollectSignals reads the node and returns its memory_pressure and condition_flaps signals.
+// Flaps are counted over the window set with WithEvaluationWindow, if any.
+func (c *NodeConditionsCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	var node corev1.Node
+	if err := c.client.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	return c.observe(&node, evaluationWindow(ctx, c.window)), nil
+}
+
+// CollectPoolSignals collects the signals of each node in turn. Reads are served by the
+// reader, which is normally the manager's cache, so no batching is needed.
+func (c *NodeConditionsCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	result := make(map[string]Signals, len(nodeNames))
+	for _, name := range nodeNames {
+		signals, err := c.CollectSignals(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = signals
+	}
+	return result, nil
+}
+
+// Observe records the node's current conditions and returns the derived signals, counting flaps
+// over the configured window. memory_pressure is as fresh as the kubelet's last condition
+// heartbeat; it is omitted when the kubelet stopped reporting and the conditions went Unknown.
+func (c *NodeConditionsCollector) Observe(node *corev1.Node) Signals {
+	return c.observe(node, c.window)
+}
+
+func (c *NodeConditionsCollector) observe(node *corev1.Node, window time.Duration) Signals {
+	signals := make(Signals, 2)
+
+	var pressure Signal
+	for _, cond := range node.Status.Conditions {
+		weight, ok := c.pressureWeights[cond.Type]
+		if !ok || (cond.Status != corev1.ConditionTrue && cond.Status != corev1.ConditionFalse) {
+			continue
+		}
+		pressure.Samples++
+		if cond.Status == corev1.ConditionTrue && weight > pressure.Value {
+			pressure.Value = weight
+		}
+		if at := conditionObservedAt(cond); at.After(pressure.ObservedAt) {
+			pressure.ObservedAt = at
+		}
+	}
+	if pressure.Samples > 0 {
+		pressure.Value = clamp(pressure.Value)
+		pressure.Source = "node-conditions"
+		signals[scorer.MetricMemoryPressure] = pressure
+	}
+
+	flaps, tracked := c.recordTransitions(node, window)
+	signals[scorer.MetricConditionFlaps] = Signal{
+		Value:      flaps,
+		ObservedAt: c.now(),
+		Source:     "node-conditions",
+		Samples:    tracked,
+	}
+	return signals
+}
+
+// conditionObservedAt is the last time the kubelet confirmed the condition.
+func conditionObservedAt(cond corev1.NodeCondition) time.Time {
+	if !cond.LastHeartbeatTime.IsZero() {
+		return cond.LastHeartbeatTime.Time
+	}
+	return cond.LastTransitionTime.Time
+}
+
+// recordTransitions updates the node's transition history and returns the normalized flap rate
+// within the window together with the number of tracked conditions present on the node.
+func (c *NodeConditionsCollector) recordTransitions(node *corev1.Node, window time.Duration) (float64, int) {
+	now := c.now()
+	cutoff := now.Add(-window)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retain = max(c.retain, window)
+
+	h, seen := c.history[node.Name]
+	if !seen {
+		h = &conditionHistory{lastTransition: make(map[corev1.NodeConditionType]time.Time)}
+		c.history[node.Name] = h
+	}
+
+	var tracked int
+	for _, cond := range node.Status.Conditions {
+		if !c.tracks(cond.Type) {
+			continue
+		}
+		tracked++
+		at := cond.LastTransitionTime.Time
+		prev, known := h.lastTransition[cond.Type]
+		h.lastTransition[cond.Type] = at
+		// The first observation only establishes a baseline; a node that just joined has
+		// fresh transition times that are not flaps.
+		if !seen || !known || !at.After(prev) {
+			continue
+		}
+		h.transitions = append(h.transitions, at)
+	}
+
+	kept := h.transitions[:0]
+	var flaps int
+	for _, t := range h.transitions {
+		if t.After(now.Add(-c.retain)) {
+			kept = append(kept, t)
+		}
+		if t.After(cutoff) {
+			flaps++
+		}
+	}
+	h.transitions = kept
+
+	return clamp(float64(flaps) / float64(c.maxFlaps)), tracked
+}
+
+// Forget drops the transition history of a node, e.g. after it was deleted.
+func (c *NodeConditionsCollector) Forget(nodeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.history, nodeName)
+}
+
+func (c *NodeConditionsCollector) tracks(t corev1.NodeConditionType) bool {
+	for _, ft := range c.flapConditions {
+		if ft == t {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp(v float64) float64 {
+	if v > 1.0 {
+		return 1.0
+	}
+	if v < 0.0 {
+		return 0.0
+	}
+	return v
+}
@@ -0,0 +1,143 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+func nodeWithConditions(name string, conds ...corev1.NodeCondition) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.NodeStatus{Conditions: conds},
+	}
+}
+
+func condition(t corev1.NodeConditionType, s corev1.ConditionStatus, at time.Time) corev1.NodeCondition {
+	return corev1.NodeCondition{Type: t, Status: s, LastTransitionTime: metav1.NewTime(at)}
+}
+
+func TestNodeConditionsCollector_Pressure(t *testing.T) {
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	node := nodeWithConditions("worker-1",
+		condition(corev1.NodeReady, corev1.ConditionTrue, start),
+		condition(corev1.NodeMemoryPressure, corev1.ConditionFalse, start),
+		condition(corev1.NodePIDPressure, corev1.ConditionTrue, start),
+	)
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := NewNodeConditionsCollector(ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build(), NodeConditionsConfig{})
+
+	got, err := c.CollectSignals(context.TODO(), "worker-1")
+	if err != nil {
+		t.Fatalf("CollectSignals() error = %v", err)
+	}
+	if p := got[scorer.MetricMemoryPressure]; p.Value != 0.5 || p.Samples != 2 || !p.ObservedAt.Equal(start) {
+		t.Errorf("memory_pressure = %+v, want 0.5 from 2 conditions observed at %v", p, start)
+	}
+	if got[scorer.MetricConditionFlaps].Value != 0 {
+		t.Errorf("condition_flaps = %v, want 0", got[scorer.MetricConditionFlaps].Value)
+	}
+}
+
+func TestNodeConditionsCollector_Flaps(t *testing.T) {
+	now := time.Now()
+	c := NewNodeConditionsCollector(nil, NodeConditionsConfig{Window: 10 * time.Minute, MaxFlaps: 4})
+	c.now = func() time.Time { return now }
+
+	observe := func(ready corev1.ConditionStatus, at time.Time) float64 {
+		return c.Observe(nodeWithConditions("worker-1", condition(corev1.NodeReady, ready, at)))[scorer.MetricConditionFlaps].Value
+	}
+
+	// Baseline: an old transition is not a flap.
+	if got := observe(corev1.ConditionTrue, now.Add(-time.Hour)); got != 0 {
+		t.Fatalf("baseline flaps = %v, want 0", got)
+	}
+	// Two transitions, one of which was not directly observed.
+	observe(corev1.ConditionFalse, now.Add(-4*time.Minute))
+	if got := observe(corev1.ConditionFalse, now.Add(-2*time.Minute)); got != 0.5 {
+		t.Errorf("flaps = %v, want 0.5", got)
+	}
+	// Re-observing the same state adds nothing.
+	if got := observe(corev1.ConditionFalse, now.Add(-2*time.Minute)); got != 0.5 {
+		t.Errorf("flaps = %v, want 0.5", got)
+	}
+
+	// Transitions age out of the window.
+	now = now.Add(7 * time.Minute)
+	if got := observe(corev1.ConditionFalse, now.Add(-9*time.Minute)); got != 0.25 {
+		t.Errorf("flaps after window = %v, want 0.25", got)
+	}
+}
+
+func TestNodeConditionsCollector_EvaluationWindow(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	client := ctrlfake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(nodeWithConditions("worker-1", condition(corev1.NodeReady, corev1.ConditionTrue, now.Add(-time.Hour)))).
+		Build()
+	c := NewNodeConditionsCollector(client, NodeConditionsConfig{Window: 5 * time.Minute, MaxFlaps: 4})
+	c.now = func() time.Time { return now }
+	long := WithEvaluationWindow(ctx, 30*time.Minute)
+
+	flaps := func(ctx context.Context) float64 {
+		t.Helper()
+		got, err := c.CollectSignals(ctx, "worker-1")
+		if err != nil {
+			t.Fatalf("CollectSignals() error = %v", err)
+		}
+		return got[scorer.MetricConditionFlaps].Value
+	}
+	transition := func(status corev1.ConditionStatus, at time.Time) {
+		t.Helper()
+		node := nodeWithConditions("worker-1", condition(corev1.NodeReady, status, at))
+		var current corev1.Node
+		if err := client.Get(ctx, types.NamespacedName{Name: "worker-1"}, &current); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		node.ResourceVersion = current.ResourceVersion
+		if err := client.Status().Update(ctx, node); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	flaps(long)
+	transition(corev1.ConditionFalse, now.Add(-20*time.Minute))
+	flaps(long)
+	transition(corev1.ConditionTrue, now.Add(-2*time.Minute))
+
+	// A policy with a 30m window counts both transitions, the default 5m window only the last.
+	if got := flaps(long); got != 0.5 {
+		t.Errorf("flaps over 30m = %v, want 0.5", got)
+	}
+	if got := flaps(ctx); got != 0.25 {
+		t.Errorf("flaps over 5m = %v, want 0.25", got)
+	}
+	// The shorter window did not drop the history the longer one needs.
+	if got := flaps(long); got != 0.5 {
+		t.Errorf("flaps over 30m = %v, want 0.5", got)
+	}
+}
+
+func TestCompositeCollector_Forget(t *testing.T) {
+	c := NewNodeConditionsCollector(nil, NodeConditionsConfig{})
+	c.Observe(nodeWithConditions("worker-1", condition(corev1.NodeReady, corev1.ConditionTrue, time.Now())))
+	composite := NewCompositeCollector(nil, Source{Name: "conditions", Collector: c})
+
+	var _ Forgetter = composite
+	composite.Forget("worker-1")
+	if _, ok := c.history["worker-1"]; ok {
+		t.Errorf("history of worker-1 was not forgotten")
+	}
+}
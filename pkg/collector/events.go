@@ -0,0 +1,353 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// DefaultKubeletErrorReasons are the event reasons counted as kubelet errors.
+var DefaultKubeletErrorReasons = []string{
+	"FailedMount",
+	"FailedAttachVolume",
+	"FailedCreatePodSandBox",
+	"FailedKillPod",
+	"ImageGCFailed",
+	"ContainerGCFailed",
+	"NodeNotReady",
+	"SystemOOM",
+	"KubeletSetupFailed",
+}
+
+// KubeletEventsConfig configures a KubeletEventsCollector.
+type KubeletEventsConfig struct {
+	// Window is the sliding window over which events are counted.
+	Window time.Duration
+
+	// Floor is the event count within Window at or below which the signal is 0.0.
+	Floor int
+
+	// Saturation is the event count within Window at or above which the signal is 1.0.
+	Saturation int
+
+	// Reasons overrides DefaultKubeletErrorReasons.
+	Reasons []string
+}
+
+// KubeletEventsCollector derives kubelet_errors from Events reported for a node.
+// Events are fed in through Observe*, normally from informers registered by SetupWithManager.
+type KubeletEventsCollector struct {
+	events     *eventWindow
+	floor      int
+	saturation int
+	reasons    map[string]bool
+}
+
+// NewKubeletEventsCollector creates an empty events collector.
+func NewKubeletEventsCollector(cfg KubeletEventsConfig) *KubeletEventsCollector {
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Minute
+	}
+	if cfg.Saturation <= cfg.Floor {
+		cfg.Saturation = cfg.Floor + 10
+	}
+	if cfg.Reasons == nil {
+		cfg.Reasons = DefaultKubeletErrorReasons
+	}
+	reasons := make(map[string]bool, len(cfg.Reasons))
+	for _, r := range cfg.Reasons {
+		reasons[r] = true
+	}
+	return &KubeletEventsCollector{
+		events:     newEventWindow(cfg.Window),
+		floor:      cfg.Floor,
+		saturation: cfg.Saturation,
+		reasons:    reasons,
+	}
+}
+
+// SetupWithManager registers informers for core/v1 and events.k8s.io/v1 Events with the manager's cache.
+func (c *KubeletEventsCollector) SetupWithManager(mgr manager.Manager) error {
+	return watchEvents(mgr, c.ObserveCoreEvent, c.ObserveEvent)
+}
+
+// ObserveCoreEvent records a core/v1 Event.
+func (c *KubeletEventsCollector) ObserveCoreEvent(e *corev1.Event) {
+	c.record(coreEventRef(e))
+}
+
+// ObserveEvent records an events.k8s.io/v1 Event.
+func (c *KubeletEventsCollector) ObserveEvent(e *eventsv1.Event) {
+	c.record(eventRefFromV1(e))
+}
+
+func (c *KubeletEventsCollector) record(ref eventRef) {
+	if !c.reasons[ref.reason] {
+		return
+	}
+	c.events.record(ref, scorer.MetricKubeletErrors)
+}
+
+// CollectSignals returns the normalized kubelet error rate for the node.
+// The window is evaluated at collection time, so the signal is always current.
+func (c *KubeletEventsCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	count := c.events.counts(nodeName)[scorer.MetricKubeletErrors]
+	return Signals{
+		scorer.MetricKubeletErrors: {
+			Value:      normalizeCount(count, c.floor, c.saturation),
+			ObservedAt: c.events.now(),
+			Source:     "events",
+			Samples:    count,
+		},
+	}, nil
+}
+
+// CollectPoolSignals returns the normalized kubelet error rate for each node.
+func (c *KubeletEventsCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	result := make(map[string]Signals, len(nodeNames))
+	for _, name := range nodeNames {
+		signals, _ := c.CollectSignals(ctx, name)
+		result[name] = signals
+	}
+	return result, nil
+}
+
+// watchEvents feeds core/v1 and events.k8s.io/v1 Events from the manager's cache to the given handlers.
+func watchEvents(mgr manager.Manager, onCore func(*corev1.Event), onV1 func(*eventsv1.Event)) error {
+	handle := func(obj interface{}) {
+		switch e := obj.(type) {
+		case *corev1.Event:
+			onCore(e)
+		case *eventsv1.Event:
+			onV1(e)
+		}
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		for _, obj := range []client.Object{&corev1.Event{}, &eventsv1.Event{}} {
+			if err := addEventHandler(ctx, mgr.GetCache(), obj, handle); err != nil {
+				return err
+			}
+		}
+		<-ctx.Done()
+		return nil
+	}))
+}
+
+func addEventHandler(ctx context.Context, informers cache.Informers, obj client.Object, handle func(interface{})) error {
+	informer, err := informers.GetInformer(ctx, obj)
+	if err != nil {
+		return fmt.Errorf("failed to get informer for %T: %w", obj, err)
+	}
+	_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    handle,
+		UpdateFunc: func(_, newObj interface{}) { handle(newObj) },
+	})
+	return err
+}
+
+// eventRef is the part of an Event the collectors care about, independent of its API version.
+type eventRef struct {
+	uid    types.UID
+	node   string
+	reason string
+	count  int32
+	at     time.Time
+	// first is when the event first occurred, zero if unknown.
+	first time.Time
+}
+
+func coreEventRef(e *corev1.Event) eventRef {
+	ref := eventRef{uid: e.UID, reason: e.Reason, count: e.Count}
+	ref.first = firstTime(e.FirstTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+
+	ref.node = e.Source.Host
+	if ref.node == "" {
+		ref.node = e.ReportingInstance
+	}
+	if ref.node == "" && e.InvolvedObject.Kind == "Node" {
+		ref.node = e.InvolvedObject.Name
+	}
+
+	ref.at = e.LastTimestamp.Time
+	if ref.at.IsZero() {
+		ref.at = e.EventTime.Time
+	}
+	if ref.at.IsZero() {
+		ref.at = e.CreationTimestamp.Time
+	}
+
+	if e.Series != nil && e.Series.Count > ref.count {
+		ref.count = e.Series.Count
+		ref.at = e.Series.LastObservedTime.Time
+	}
+	return ref
+}
+
+func eventRefFromV1(e *eventsv1.Event) eventRef {
+	ref := eventRef{uid: e.UID, reason: e.Reason, count: e.DeprecatedCount}
+	ref.first = firstTime(e.DeprecatedFirstTimestamp.Time, e.EventTime.Time, e.CreationTimestamp.Time)
+
+	ref.node = e.ReportingInstance
+	if ref.node == "" {
+		ref.node = e.DeprecatedSource.Host
+	}
+	if ref.node == "" && e.Regarding.Kind == "Node" {
+		ref.node = e.Regarding.Name
+	}
+
+	ref.at = e.EventTime.Time
+	if ref.at.IsZero() {
+		ref.at = e.DeprecatedLastTimestamp.Time
+	}
+	if ref.at.IsZero() {
+		ref.at = e.CreationTimestamp.Time
+	}
+
+	if e.Series != nil && e.Series.Count > ref.count {
+		ref.count = e.Series.Count
+		ref.at = e.Series.LastObservedTime.Time
+	}
+	return ref
+}
+
+// firstTime returns the first non-zero time.
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// eventWindow counts event occurrences per node and metric over a sliding window.
+// The same event is visible through both core/v1 and events.k8s.io/v1, so occurrences are
+// de-duplicated by UID and only the growth of the event's count is added to the window.
+// An event first seen with a count, as every event is when the informer lists them after a
+// restart, only adds the occurrences known to fall within the window.
+type eventWindow struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu          sync.Mutex
+	seen        map[types.UID]seenEvent
+	occurrences map[string]map[scorer.MetricName][]occurrence
+}
+
+type seenEvent struct {
+	count    int32
+	lastSeen time.Time
+}
+
+type occurrence struct {
+	at    time.Time
+	count int32
+}
+
+func newEventWindow(window time.Duration) *eventWindow {
+	return &eventWindow{
+		window:      window,
+		now:         time.Now,
+		seen:        make(map[types.UID]seenEvent),
+		occurrences: make(map[string]map[scorer.MetricName][]occurrence),
+	}
+}
+
+func (w *eventWindow) record(ref eventRef, metric scorer.MetricName) {
+	if ref.node == "" {
+		return
+	}
+	count := ref.count
+	if count < 1 {
+		count = 1
+	}
+
+	now := w.now()
+	at := ref.at
+	if at.IsZero() || at.After(now) {
+		at = now
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	start := now.Add(-w.window)
+	prev, known := w.seen[ref.uid]
+	w.seen[ref.uid] = seenEvent{count: count, lastSeen: now}
+	if count <= prev.count || at.Before(start) {
+		return
+	}
+	added := count - prev.count
+	if !known && (ref.first.IsZero() || ref.first.Before(start)) {
+		// Earlier occurrences may lie anywhere before; only the last one is known to be recent.
+		added = 1
+	}
+	byMetric, ok := w.occurrences[ref.node]
+	if !ok {
+		byMetric = make(map[scorer.MetricName][]occurrence)
+		w.occurrences[ref.node] = byMetric
+	}
+	byMetric[metric] = append(byMetric[metric], occurrence{at: at, count: added})
+}
+
+// counts returns the number of occurrences per metric within the window for the node.
+func (w *eventWindow) counts(nodeName string) map[scorer.MetricName]int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.prune()
+
+	result := make(map[scorer.MetricName]int)
+	for metric, occ := range w.occurrences[nodeName] {
+		for _, o := range occ {
+			result[metric] += int(o.count)
+		}
+	}
+	return result
+}
+
+// prune drops occurrences and de-duplication entries that fell out of the window.
+func (w *eventWindow) prune() {
+	cutoff := w.now().Add(-w.window)
+	for node, byMetric := range w.occurrences {
+		for metric, occ := range byMetric {
+			kept := occ[:0]
+			for _, o := range occ {
+				if o.at.After(cutoff) {
+					kept = append(kept, o)
+				}
+			}
+			if len(kept) == 0 {
+				delete(byMetric, metric)
+				continue
+			}
+			byMetric[metric] = kept
+		}
+		if len(byMetric) == 0 {
+			delete(w.occurrences, node)
+		}
+	}
+	for uid, seen := range w.seen {
+		if seen.lastSeen.Before(cutoff) {
+			delete(w.seen, uid)
+		}
+	}
+}
+
+// normalizeCount maps an event count linearly to 0.0 at floor and 1.0 at saturation.
+func normalizeCount(count, floor, saturation int) float64 {
+	if count <= floor {
+		return 0.0
+	}
+	return clamp(float64(count-floor) / float64(saturation-floor))
+}
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+func TestKubeletEventsCollector(t *testing.T) {
+	now := time.Now()
+	c := NewKubeletEventsCollector(KubeletEventsConfig{Window: 10 * time.Minute, Floor: 1, Saturation: 5})
+	c.events.now = func() time.Time { return now }
+
+	kubeletErrors := func(node string) float64 {
+		signals, err := c.CollectSignals(context.TODO(), node)
+		if err != nil {
+			t.Fatalf("CollectSignals() error = %v", err)
+		}
+		return signals[scorer.MetricKubeletErrors].Value
+	}
+
+	mount := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "uid-1"},
+		Reason:         "FailedMount",
+		Source:         corev1.EventSource{Component: "kubelet", Host: "worker-1"},
+		Count:          2,
+		FirstTimestamp: metav1.NewTime(now.Add(-2 * time.Minute)),
+		LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+	}
+	c.ObserveCoreEvent(mount)
+
+	// Ignored: unrelated reason, and an event older than the window.
+	c.ObserveCoreEvent(&corev1.Event{
+		ObjectMeta:    metav1.ObjectMeta{UID: "uid-2"},
+		Reason:        "Pulled",
+		Source:        corev1.EventSource{Host: "worker-1"},
+		LastTimestamp: metav1.NewTime(now),
+	})
+	c.ObserveCoreEvent(&corev1.Event{
+		ObjectMeta:    metav1.ObjectMeta{UID: "uid-3"},
+		Reason:        "ImageGCFailed",
+		Source:        corev1.EventSource{Host: "worker-1"},
+		LastTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+	})
+
+	// 2 events, floor 1, saturation 5.
+	if got := kubeletErrors("worker-1"); got != 0.25 {
+		t.Errorf("kubelet_errors = %v, want 0.25", got)
+	}
+
+	// The same event seen through events.k8s.io only adds its new occurrences.
+	c.ObserveEvent(&eventsv1.Event{
+		ObjectMeta:        metav1.ObjectMeta{UID: "uid-1"},
+		Reason:            "FailedMount",
+		ReportingInstance: "worker-1",
+		Series:            &eventsv1.EventSeries{Count: 4, LastObservedTime: metav1.NewMicroTime(now)},
+	})
+	if got := kubeletErrors("worker-1"); got != 0.75 {
+		t.Errorf("kubelet_errors = %v, want 0.75", got)
+	}
+
+	// Node-scoped events without a source host are attributed to the involved node.
+	c.ObserveCoreEvent(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "uid-4"},
+		Reason:         "NodeNotReady",
+		InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "worker-2"},
+		Count:          10,
+		FirstTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+		LastTimestamp:  metav1.NewTime(now),
+	})
+	if got := kubeletErrors("worker-2"); got != 1.0 {
+		t.Errorf("kubelet_errors = %v, want 1.0", got)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if got := kubeletErrors("worker-1"); got != 0 {
+		t.Errorf("kubelet_errors after window = %v, want 0", got)
+	}
+}
+
+func TestKubeletEventsCollector_Relist(t *testing.T) {
+	now := time.Now()
+	c := NewKubeletEventsCollector(KubeletEventsConfig{Window: 10 * time.Minute, Floor: 1, Saturation: 5})
+	c.events.now = func() time.Time { return now }
+
+	// After a restart the informer lists an event that has been recurring for hours.
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "uid-1"},
+		Reason:         "FailedMount",
+		Source:         corev1.EventSource{Component: "kubelet", Host: "worker-1"},
+		Count:          50,
+		FirstTimestamp: metav1.NewTime(now.Add(-5 * time.Hour)),
+		LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+	}
+	c.ObserveCoreEvent(event)
+	if got := c.events.counts("worker-1")[scorer.MetricKubeletErrors]; got != 1 {
+		t.Errorf("kubelet errors after relist = %d, want 1", got)
+	}
+
+	// Occurrences after the relist count in full.
+	event = event.DeepCopy()
+	event.Count = 53
+	event.LastTimestamp = metav1.NewTime(now)
+	c.ObserveCoreEvent(event)
+	if got := c.events.counts("worker-1")[scorer.MetricKubeletErrors]; got != 4 {
+		t.Errorf("kubelet errors = %d, want 4", got)
+	}
+}
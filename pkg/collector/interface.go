@@ -2,12 +2,71 @@ package collector
 
 import (
 	"context"
+	"time"
 
 	"github.com/example/self-healing-nodepool/pkg/scorer"
 )
 
+// Signal is a single observation of a health metric.
+type Signal struct {
+	// Value is the observed value, normally between 0.0 (healthy) and 1.0 (unhealthy).
+	Value float64
+	// ObservedAt is when the underlying data was produced, not when it was collected.
+	ObservedAt time.Time
+	// Source names the collector that produced the signal.
+	Source string
+	// Samples is the number of raw samples, series or events the value was derived from.
+	Samples int
+}
+
+// Signals holds the observations for a single node, keyed by metric.
+type Signals map[scorer.MetricName]Signal
+
+// Values returns the bare values of all signals.
+func (s Signals) Values() map[scorer.MetricName]float64 {
+	values := make(map[scorer.MetricName]float64, len(s))
+	for metric, sig := range s {
+		values[metric] = sig.Value
+	}
+	return values
+}
+
 // NodeSignalCollector defines the interface for gathering health signals from a node.
 type NodeSignalCollector interface {
 	// CollectSignals fetches the health signals for a specific node.
-	CollectSignals(ctx context.Context, nodeName string) (map[scorer.MetricName]float64, error)
+	// Metrics the collector has no data for are omitted rather than reported as 0.0.
+	CollectSignals(ctx context.Context, nodeName string) (Signals, error)
+}
+
+// BatchSignalCollector gathers health signals for many nodes in a single pass.
+type BatchSignalCollector interface {
+	// CollectPoolSignals fetches the health signals for each of the given nodes, keyed by node name.
+	CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error)
+}
+
+// NodeLister returns the names of the nodes whose signals should be collected.
+type NodeLister func(ctx context.Context) ([]string, error)
+
+// Forgetter is implemented by collectors that keep per-node state, so the state of nodes that are
+// gone or no longer evaluated can be dropped.
+type Forgetter interface {
+	// Forget drops the state kept for the node.
+	Forget(nodeName string)
+}
+
+type evaluationWindowKey struct{}
+
+// WithEvaluationWindow returns a context asking collectors that aggregate over time, like the
+// NodeConditionsCollector, to aggregate over the window, normally the policy's evaluation window.
+// A window of zero leaves the collectors' defaults in place.
+func WithEvaluationWindow(ctx context.Context, window time.Duration) context.Context {
+	return context.WithValue(ctx, evaluationWindowKey{}, window)
+}
+
+// evaluationWindow returns the window set with WithEvaluationWindow, or fallback if none is.
+func evaluationWindow(ctx context.Context, fallback time.Duration) time.Duration {
+	if window, ok := ctx.Value(evaluationWindowKey{}).(time.Duration); ok && window > 0 {
+		return window
+	}
+	return fallback
 }
@@ -0,0 +1,28 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	circuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nodehealer_telemetry_circuit_state",
+		Help: "State of the telemetry circuit breaker (0 = closed, 1 = half-open, 2 = open).",
+	}, []string{"source"})
+
+	collectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nodehealer_telemetry_collect_duration_seconds",
+		Help:    "Latency of signal collection calls to the telemetry upstream.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"source"})
+
+	collectFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nodehealer_telemetry_collect_failures_total",
+		Help: "Number of failed or too slow signal collection calls to the telemetry upstream.",
+	}, []string{"source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(circuitState, collectDuration, collectFailures)
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// DefaultNPDConditionMetrics maps node-problem-detector conditions to signals.
+var DefaultNPDConditionMetrics = map[corev1.NodeConditionType]scorer.MetricName{
+	"KernelDeadlock":            scorer.MetricKernelDeadlock,
+	"ReadonlyFilesystem":        scorer.MetricReadonlyFilesystem,
+	"FrequentKubeletRestart":    scorer.MetricFrequentKubeletRestart,
+	"FrequentContainerdRestart": scorer.MetricFrequentContainerdRestart,
+	"FrequentDockerRestart":     scorer.MetricFrequentContainerdRestart,
+}
+
+// DefaultNPDEventMetrics maps node-problem-detector event reasons to signals.
+var DefaultNPDEventMetrics = map[string]scorer.MetricName{
+	"TaskHung":             scorer.MetricKernelDeadlock,
+	"KernelOops":           scorer.MetricKernelDeadlock,
+	"AUFSUmountHung":       scorer.MetricKernelDeadlock,
+	"DockerHung":           scorer.MetricKernelDeadlock,
+	"FilesystemIsReadOnly": scorer.MetricReadonlyFilesystem,
+	"Ext4Error":            scorer.MetricReadonlyFilesystem,
+}
+
+// NPDConfig configures an NPDCollector.
+type NPDConfig struct {
+	// ConditionMetrics overrides DefaultNPDConditionMetrics.
+	ConditionMetrics map[corev1.NodeConditionType]scorer.MetricName
+
+	// EventMetrics overrides DefaultNPDEventMetrics.
+	EventMetrics map[string]scorer.MetricName
+
+	// EventWindow is the sliding window over which events are counted.
+	EventWindow time.Duration
+
+	// EventSaturation is the event count within EventWindow that maps to a signal of 1.0.
+	EventSaturation int
+}
+
+// NPDCollector turns node-problem-detector conditions and events into signals.
+// A condition that is True maps to 1.0, so problems NPD reports while the kubelet keeps
+// heartbeating (e.g. a kernel deadlock on a Ready node) still show up in the score.
+// Events raise the same signals in proportion to how often they occurred recently.
+type NPDCollector struct {
+	client           client.Reader
+	conditionMetrics map[corev1.NodeConditionType]scorer.MetricName
+	eventMetrics     map[string]scorer.MetricName
+	events           *eventWindow
+	saturation       int
+}
+
+// NewNPDCollector creates a collector reading node conditions from the given reader.
+func NewNPDCollector(c client.Reader, cfg NPDConfig) *NPDCollector {
+	if cfg.ConditionMetrics == nil {
+		cfg.ConditionMetrics = DefaultNPDConditionMetrics
+	}
+	if cfg.EventMetrics == nil {
+		cfg.EventMetrics = DefaultNPDEventMetrics
+	}
+	if cfg.EventWindow <= 0 {
+		cfg.EventWindow = 30 * time.Minute
+	}
+	if cfg.EventSaturation <= 0 {
+		cfg.EventSaturation = 3
+	}
+	return &NPDCollector{
+		client:           c,
+		conditionMetrics: cfg.ConditionMetrics,
+		eventMetrics:     cfg.EventMetrics,
+		events:           newEventWindow(cfg.EventWindow),
+		saturation:       cfg.EventSaturation,
+	}
+}
+
+// SetupWithManager registers informers for node-problem-detector events with the manager's cache.
+func (c *NPDCollector) SetupWithManager(mgr manager.Manager) error {
+	return watchEvents(mgr, c.ObserveCoreEvent, c.ObserveEvent)
+}
+
+// ObserveCoreEvent records a core/v1 Event.
+func (c *NPDCollector) ObserveCoreEvent(e *corev1.Event) {
+	c.record(coreEventRef(e))
+}
+
+// ObserveEvent records an events.k8s.io/v1 Event.
+func (c *NPDCollector) ObserveEvent(e *eventsv1.Event) {
+	c.record(eventRefFromV1(e))
+}
+
+func (c *NPDCollector) record(ref eventRef) {
+	if metric, ok := c.eventMetrics[ref.reason]; ok {
+		c.events.record(ref, metric)
+	}
+}
+
+// CollectSignals reads the node and returns the node-problem-detector signals.
+// A signal is only reported when the node carries its condition or a matching event occurred,
+// so nodes without node-problem-detector report nothing rather than a healthy 0.0.
+func (c *NPDCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	var node corev1.Node
+	if err := c.client.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	return c.Observe(&node), nil
+}
+
+// CollectPoolSignals collects the signals of each node in turn from the reader.
+func (c *NPDCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	result := make(map[string]Signals, len(nodeNames))
+	for _, name := range nodeNames {
+		signals, err := c.CollectSignals(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = signals
+	}
+	return result, nil
+}
+
+// Observe derives the signals from the node's conditions and recent events.
+func (c *NPDCollector) Observe(node *corev1.Node) Signals {
+	signals := make(Signals)
+	for _, cond := range node.Status.Conditions {
+		metric, ok := c.conditionMetrics[cond.Type]
+		if !ok || (cond.Status != corev1.ConditionTrue && cond.Status != corev1.ConditionFalse) {
+			continue
+		}
+		sig := signals[metric]
+		sig.Source = "npd"
+		sig.Samples++
+		if cond.Status == corev1.ConditionTrue {
+			sig.Value = 1.0
+		}
+		if at := conditionObservedAt(cond); at.After(sig.ObservedAt) {
+			sig.ObservedAt = at
+		}
+		signals[metric] = sig
+	}
+
+	now := c.events.now()
+	for metric, count := range c.events.counts(node.Name) {
+		sig := signals[metric]
+		if v := normalizeCount(count, 0, c.saturation); v > sig.Value {
+			sig.Value = v
+			sig.ObservedAt = now
+		}
+		sig.Source = "npd"
+		sig.Samples += count
+		signals[metric] = sig
+	}
+	return signals
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+func TestNPDCollector_Observe(t *testing.T) {
+	now := time.Now()
+	c := NewNPDCollector(nil, NPDConfig{EventSaturation: 2})
+
+	// Kernel deadlock while the kubelet keeps reporting Ready.
+	node := nodeWithConditions("worker-1",
+		condition(corev1.NodeReady, corev1.ConditionTrue, now.Add(-time.Hour)),
+		condition("KernelDeadlock", corev1.ConditionTrue, now.Add(-time.Minute)),
+		condition("ReadonlyFilesystem", corev1.ConditionFalse, now.Add(-time.Hour)),
+		condition("FrequentKubeletRestart", corev1.ConditionUnknown, now.Add(-time.Hour)),
+	)
+	c.ObserveCoreEvent(&corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: "uid-1"},
+		Reason:         "Ext4Error",
+		Source:         corev1.EventSource{Component: "kernel-monitor", Host: "worker-1"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Node", Name: "worker-1"},
+		LastTimestamp:  metav1.NewTime(now),
+	})
+
+	got := c.Observe(node).Values()
+	want := map[scorer.MetricName]float64{
+		scorer.MetricKernelDeadlock:     1.0,
+		scorer.MetricReadonlyFilesystem: 0.5,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Observe() = %v, want %v", got, want)
+	}
+
+	// Nodes without node-problem-detector report nothing.
+	if got := c.Observe(nodeWithConditions("worker-2", condition(corev1.NodeReady, corev1.ConditionTrue, now))); len(got) != 0 {
+		t.Errorf("Observe() = %v, want no signals", got)
+	}
+}
@@ -1,26 +1,470 @@
 package collector
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
 
 	"github.com/example/self-healing-nodepool/pkg/scorer"
 )
 
+// DefaultPrometheusQueries are the PromQL templates used when no queries are configured.
+// Each template is rendered with QueryParams and must evaluate to a value between 0.0 and 1.0.
+var DefaultPrometheusQueries = map[scorer.MetricName]string{
+	// Fraction of wall time the busiest disk spent servicing IO.
+	scorer.MetricDiskIOWait: `max(rate(node_disk_io_time_seconds_total{instance=~"{{ reQuote .Instance }}(:[0-9]+)?"}[5m]))`,
+	// Fraction of received packets that were dropped.
+	scorer.MetricNetworkDrops: `sum(rate(node_network_receive_drop_total{instance=~"{{ reQuote .Instance }}(:[0-9]+)?"}[5m])) / clamp_min(sum(rate(node_network_receive_packets_total{instance=~"{{ reQuote .Instance }}(:[0-9]+)?"}[5m])), 1)`,
+	// Fraction of container runtime operations that failed.
+	scorer.MetricKubeletErrors: `sum(rate(kubelet_runtime_operations_errors_total{node="{{ .Node }}"}[5m])) / clamp_min(sum(rate(kubelet_runtime_operations_total{node="{{ .Node }}"}[5m])), 1)`,
+}
+
+// DefaultPrometheusPoolQueries are the vector queries used to collect signals for many nodes at once.
+// Each query must return at most one series per node, identified by the query's Label.
+var DefaultPrometheusPoolQueries = map[scorer.MetricName]PoolQuery{
+	scorer.MetricDiskIOWait: {
+		Query: `max by (instance) (rate(node_disk_io_time_seconds_total[5m]))`,
+		Label: "instance",
+	},
+	scorer.MetricNetworkDrops: {
+		Query: `sum by (instance) (rate(node_network_receive_drop_total[5m])) / clamp_min(sum by (instance) (rate(node_network_receive_packets_total[5m])), 1)`,
+		Label: "instance",
+	},
+	scorer.MetricKubeletErrors: {
+		Query: `sum by (node) (rate(kubelet_runtime_operations_errors_total[5m])) / clamp_min(sum by (node) (rate(kubelet_runtime_operations_total[5m])), 1)`,
+		Label: "node",
+	},
+}
+
+// PoolQuery is a PromQL template that returns one series per node.
+// The template is rendered with PoolQueryParams.
+type PoolQuery struct {
+	// Query is the PromQL template.
+	Query string
+	// Label is the series label identifying the node. Its value may be the node name
+	// or the node's instance, with or without a port.
+	Label string
+}
+
+// PoolQueryParams is the data a PoolQuery template is rendered with.
+type PoolQueryParams struct {
+	// NodeRegex matches the names of all requested nodes.
+	NodeRegex string
+	// InstanceRegex matches the instances of all requested nodes.
+	InstanceRegex string
+}
+
+// QueryParams is the data a PromQL template is rendered with.
+type QueryParams struct {
+	// Node is the Kubernetes node name.
+	Node string
+	// Instance is the value of the Prometheus instance label for the node.
+	Instance string
+}
+
+// InstanceMapper maps a Kubernetes node name to the Prometheus instance label value.
+type InstanceMapper func(nodeName string) string
+
+// IdentityInstanceMapper uses the node name as the instance label value.
+func IdentityInstanceMapper(nodeName string) string {
+	return nodeName
+}
+
+// FormatInstanceMapper builds the instance label from a format string, e.g. "%s:9100".
+func FormatInstanceMapper(format string) InstanceMapper {
+	return func(nodeName string) string {
+		return fmt.Sprintf(format, nodeName)
+	}
+}
+
+// StaticInstanceMapper looks the instance up in a fixed table, falling back to the node name.
+func StaticInstanceMapper(instances map[string]string) InstanceMapper {
+	return func(nodeName string) string {
+		if instance, ok := instances[nodeName]; ok {
+			return instance
+		}
+		return nodeName
+	}
+}
+
+// BasicAuth holds HTTP basic authentication credentials.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// TLSConfig configures the TLS connection to Prometheus.
+type TLSConfig struct {
+	// CAFile is a PEM bundle used to verify the server certificate.
+	CAFile string
+	// CertFile and KeyFile enable client certificate authentication.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string
+	// InsecureSkipVerify disables server certificate verification.
+	InsecureSkipVerify bool
+}
+
+// PrometheusConfig configures a PrometheusCollector.
+type PrometheusConfig struct {
+	// URL is the base address of the Prometheus HTTP API.
+	URL string
+
+	// Queries maps each metric to a PromQL template. Defaults to DefaultPrometheusQueries.
+	Queries map[scorer.MetricName]string
+
+	// PoolQueries maps each metric to a vector query used by CollectPoolSignals.
+	// Defaults to DefaultPrometheusPoolQueries.
+	PoolQueries map[scorer.MetricName]PoolQuery
+
+	// InstanceMapper maps node names to instance labels. Defaults to IdentityInstanceMapper.
+	InstanceMapper InstanceMapper
+
+	// Timeout bounds each query. Zero means no per-query timeout.
+	Timeout time.Duration
+
+	// BearerToken or BearerTokenFile set the Authorization header. The file is re-read on every request
+	// so rotated service account tokens are picked up.
+	BearerToken     string
+	BearerTokenFile string
+
+	// BasicAuth sets HTTP basic authentication. Mutually exclusive with bearer tokens.
+	BasicAuth *BasicAuth
+
+	// TLS configures the transport for https URLs.
+	TLS TLSConfig
+}
+
+type poolQuery struct {
+	tmpl  *template.Template
+	label model.LabelName
+}
+
+// PrometheusCollector collects node signals using Prometheus instant queries.
 type PrometheusCollector struct {
-	// In a real impl, this would hold the PromAPI client
+	api            promv1.API
+	queries        map[scorer.MetricName]*template.Template
+	poolQueries    map[scorer.MetricName]poolQuery
+	instanceMapper InstanceMapper
+	timeout        time.Duration
 }
 
-func NewPrometheusCollector(url string) *PrometheusCollector {
-	return &PrometheusCollector{}
+var queryFuncs = template.FuncMap{
+	// reQuote escapes a value for use inside a double-quoted PromQL regex matcher.
+	"reQuote": func(s string) string {
+		return escapeBackslashes(regexp.QuoteMeta(s))
+	},
 }
 
-func (c *PrometheusCollector) CollectSignals(ctx context.Context, nodeName string) (map[scorer.MetricName]float64, error) {
-	// MOCK IMPLEMENTATION
-	// In reality, query Prometheus for node_disk_io_time_seconds_total etc.
-	// CRITICAL: Returning 0.0 to avoid accidental self-healing triggers during development/demo.
-	return map[scorer.MetricName]float64{
-		scorer.MetricDiskIOWait:    0.0,
-		scorer.MetricNetworkDrops:  0.0,
-		scorer.MetricKubeletErrors: 0.0,
+// NewPrometheusCollector creates a collector for the Prometheus server described by cfg.
+func NewPrometheusCollector(cfg PrometheusConfig) (*PrometheusCollector, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("prometheus url must be set")
+	}
+	if cfg.BasicAuth != nil && (cfg.BearerToken != "" || cfg.BearerTokenFile != "") {
+		return nil, fmt.Errorf("basic auth and bearer token are mutually exclusive")
+	}
+
+	rt, err := newRoundTripper(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := api.NewClient(api.Config{Address: cfg.URL, RoundTripper: rt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus client: %w", err)
+	}
+
+	queries := cfg.Queries
+	if len(queries) == 0 {
+		queries = DefaultPrometheusQueries
+	}
+	templates := make(map[scorer.MetricName]*template.Template, len(queries))
+	for metric, query := range queries {
+		tmpl, err := template.New(string(metric)).Funcs(queryFuncs).Option("missingkey=error").Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query template for %s: %w", metric, err)
+		}
+		templates[metric] = tmpl
+	}
+
+	pool := cfg.PoolQueries
+	if len(pool) == 0 {
+		pool = DefaultPrometheusPoolQueries
+	}
+	poolTemplates := make(map[scorer.MetricName]poolQuery, len(pool))
+	for metric, pq := range pool {
+		if pq.Label == "" {
+			return nil, fmt.Errorf("pool query for %s must set a node label", metric)
+		}
+		tmpl, err := template.New(string(metric)).Funcs(queryFuncs).Option("missingkey=error").Parse(pq.Query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool query template for %s: %w", metric, err)
+		}
+		poolTemplates[metric] = poolQuery{tmpl: tmpl, label: model.LabelName(pq.Label)}
+	}
+
+	mapper := cfg.InstanceMapper
+	if mapper == nil {
+		mapper = IdentityInstanceMapper
+	}
+
+	return &PrometheusCollector{
+		api:            promv1.NewAPI(client),
+		queries:        templates,
+		poolQueries:    poolTemplates,
+		instanceMapper: mapper,
+		timeout:        cfg.Timeout,
 	}, nil
 }
+
+// QueriesPerCollection returns how many queries one collection runs in sequence, at most.
+// A collection may take up to that many times the per-query timeout.
+func (c *PrometheusCollector) QueriesPerCollection() int {
+	return max(len(c.queries), len(c.poolQueries))
+}
+
+// CollectSignals runs one instant query per configured metric for the node.
+// Metrics whose query returns no samples are omitted from the result.
+func (c *PrometheusCollector) CollectSignals(ctx context.Context, nodeName string) (Signals, error) {
+	params := QueryParams{
+		Node:     nodeName,
+		Instance: c.instanceMapper(nodeName),
+	}
+
+	signals := make(Signals, len(c.queries))
+	for metric, tmpl := range c.queries {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, params); err != nil {
+			return nil, fmt.Errorf("failed to render query for %s: %w", metric, err)
+		}
+
+		sig, ok, err := c.query(ctx, buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s for node %s: %w", metric, nodeName, err)
+		}
+		if ok {
+			signals[metric] = sig
+		}
+	}
+	return signals, nil
+}
+
+// CollectPoolSignals runs one vector query per configured metric and fans the
+// resulting series out to the requested nodes by their node label.
+// Every requested node is present in the result, possibly with no signals.
+func (c *PrometheusCollector) CollectPoolSignals(ctx context.Context, nodeNames []string) (map[string]Signals, error) {
+	result := make(map[string]Signals, len(nodeNames))
+	index := make(map[string]string, 2*len(nodeNames))
+	nodePatterns := make([]string, 0, len(nodeNames))
+	instancePatterns := make([]string, 0, len(nodeNames))
+	for _, name := range nodeNames {
+		result[name] = Signals{}
+		instance := c.instanceMapper(name)
+		index[name] = name
+		index[instance] = name
+		index[stripPort(instance)] = name
+		nodePatterns = append(nodePatterns, regexp.QuoteMeta(name))
+		instancePatterns = append(instancePatterns, regexp.QuoteMeta(instance))
+	}
+	if len(nodeNames) == 0 {
+		return result, nil
+	}
+
+	params := PoolQueryParams{
+		NodeRegex:     escapeBackslashes(strings.Join(nodePatterns, "|")),
+		InstanceRegex: escapeBackslashes(strings.Join(instancePatterns, "|")),
+	}
+	for metric, pq := range c.poolQueries {
+		var buf bytes.Buffer
+		if err := pq.tmpl.Execute(&buf, params); err != nil {
+			return nil, fmt.Errorf("failed to render pool query for %s: %w", metric, err)
+		}
+
+		vector, err := c.queryVector(ctx, buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s for node pool: %w", metric, err)
+		}
+		for _, sample := range vector {
+			labelValue := string(sample.Metric[pq.label])
+			node, ok := index[labelValue]
+			if !ok {
+				node, ok = index[stripPort(labelValue)]
+			}
+			if !ok {
+				continue
+			}
+			prev, seen := result[node][metric]
+			if sig, ok := mergeSample(prev, seen, sample); ok {
+				result[node][metric] = sig
+			}
+		}
+	}
+	return result, nil
+}
+
+// mergeSample folds a sample into a signal, keeping the worst (largest) value.
+// NaN samples are ignored.
+func mergeSample(sig Signal, seen bool, sample *model.Sample) (Signal, bool) {
+	val := float64(sample.Value)
+	if math.IsNaN(val) {
+		return sig, seen
+	}
+	if !seen {
+		return Signal{Value: val, ObservedAt: sample.Timestamp.Time(), Source: "prometheus", Samples: 1}, true
+	}
+	sig.Samples++
+	if val > sig.Value {
+		sig.Value = val
+		sig.ObservedAt = sample.Timestamp.Time()
+	}
+	return sig, true
+}
+
+// queryVector evaluates an instant query that must return a vector.
+func (c *PrometheusCollector) queryVector(ctx context.Context, query string) (model.Vector, error) {
+	var opts []promv1.Option
+	if c.timeout > 0 {
+		opts = append(opts, promv1.WithTimeout(c.timeout))
+	}
+	result, _, err := c.api.Query(ctx, query, time.Now(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("expected vector result, got %s", result.Type())
+	}
+	return vector, nil
+}
+
+func stripPort(instance string) string {
+	if host, _, err := net.SplitHostPort(instance); err == nil {
+		return host
+	}
+	return instance
+}
+
+func escapeBackslashes(s string) string {
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// query evaluates an instant query and reduces the result to a single signal.
+// When the result holds several series the worst (largest) value wins.
+func (c *PrometheusCollector) query(ctx context.Context, query string) (Signal, bool, error) {
+	var opts []promv1.Option
+	if c.timeout > 0 {
+		opts = append(opts, promv1.WithTimeout(c.timeout))
+	}
+	result, _, err := c.api.Query(ctx, query, time.Now(), opts...)
+	if err != nil {
+		return Signal{}, false, err
+	}
+
+	switch v := result.(type) {
+	case *model.Scalar:
+		if math.IsNaN(float64(v.Value)) {
+			return Signal{}, false, nil
+		}
+		return Signal{Value: float64(v.Value), ObservedAt: v.Timestamp.Time(), Source: "prometheus", Samples: 1}, true, nil
+	case model.Vector:
+		var (
+			sig   Signal
+			found bool
+		)
+		for _, sample := range v {
+			sig, found = mergeSample(sig, found, sample)
+		}
+		return sig, found, nil
+	default:
+		return Signal{}, false, fmt.Errorf("unsupported result type %s", result.Type())
+	}
+}
+
+func newRoundTripper(cfg PrometheusConfig) (http.RoundTripper, error) {
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+
+	if strings.HasPrefix(cfg.URL, "https://") || cfg.TLS != (TLSConfig{}) {
+		tlsConfig, err := newTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	switch {
+	case cfg.BasicAuth != nil:
+		rt = &authRoundTripper{next: rt, basicAuth: cfg.BasicAuth}
+	case cfg.BearerToken != "" || cfg.BearerTokenFile != "":
+		rt = &authRoundTripper{next: rt, token: cfg.BearerToken, tokenFile: cfg.BearerTokenFile}
+	}
+	return rt, nil
+}
+
+func newTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// authRoundTripper adds basic or bearer authentication to outgoing requests.
+type authRoundTripper struct {
+	next      http.RoundTripper
+	basicAuth *BasicAuth
+	token     string
+	tokenFile string
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if rt.basicAuth != nil {
+		req.SetBasicAuth(rt.basicAuth.Username, rt.basicAuth.Password)
+		return rt.next.RoundTrip(req)
+	}
+
+	token := rt.token
+	if rt.tokenFile != "" {
+		b, err := os.ReadFile(rt.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return rt.next.RoundTrip(req)
+}
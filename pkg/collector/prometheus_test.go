@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// fakePrometheus is a minimal stand-in for the Prometheus query API.
+// It answers instant queries from a table keyed by the exact PromQL string.
+type fakePrometheus struct {
+	t       *testing.T
+	results map[string]string
+	queries []string
+	auth    []string
+}
+
+func (f *fakePrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/query" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		f.t.Fatalf("failed to parse form: %v", err)
+	}
+	query := r.Form.Get("query")
+	f.queries = append(f.queries, query)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	result, ok := f.results[query]
+	if !ok {
+		result = `{"resultType":"vector","result":[]}`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]json.RawMessage{
+		"status": json.RawMessage(`"success"`),
+		"data":   json.RawMessage(result),
+	})
+}
+
+func vectorResult(instance string, values ...string) string {
+	var samples []string
+	for _, v := range values {
+		samples = append(samples, `{"metric":{"instance":"`+instance+`"},"value":[1700000000,"`+v+`"]}`)
+	}
+	return `{"resultType":"vector","result":[` + strings.Join(samples, ",") + `]}`
+}
+
+func TestPrometheusCollector_CollectSignals(t *testing.T) {
+	fake := &fakePrometheus{
+		t: t,
+		results: map[string]string{
+			`io{instance="10.0.0.1:9100"}`:    vectorResult("10.0.0.1:9100", "0.2", "0.7"),
+			`drops{instance="10.0.0.1:9100"}`: vectorResult("10.0.0.1:9100", "NaN"),
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c, err := NewPrometheusCollector(PrometheusConfig{
+		URL: server.URL,
+		Queries: map[scorer.MetricName]string{
+			scorer.MetricDiskIOWait:    `io{instance="{{ .Instance }}"}`,
+			scorer.MetricNetworkDrops:  `drops{instance="{{ .Instance }}"}`,
+			scorer.MetricKubeletErrors: `errors{node="{{ .Node }}"}`,
+		},
+		InstanceMapper: StaticInstanceMapper(map[string]string{"worker-1": "10.0.0.1:9100"}),
+		BearerToken:    "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+
+	got, err := c.CollectSignals(context.TODO(), "worker-1")
+	if err != nil {
+		t.Fatalf("CollectSignals() error = %v", err)
+	}
+
+	// The worst sample wins, NaN and empty results are treated as missing.
+	want := Signals{scorer.MetricDiskIOWait: {
+		Value:      0.7,
+		ObservedAt: time.Unix(1700000000, 0),
+		Source:     "prometheus",
+		Samples:    2,
+	}}
+	if !signalsEqual(got, want) {
+		t.Errorf("CollectSignals() = %v, want %v", got, want)
+	}
+
+	if len(fake.queries) != 3 {
+		t.Fatalf("expected 3 queries, got %d: %v", len(fake.queries), fake.queries)
+	}
+	for _, q := range fake.queries {
+		if q == `errors{node="worker-1"}` {
+			continue
+		}
+		if !strings.Contains(q, "10.0.0.1:9100") {
+			t.Errorf("query %q was not rendered with the mapped instance", q)
+		}
+	}
+	for _, a := range fake.auth {
+		if a != "Bearer secret" {
+			t.Errorf("Authorization header = %q, want %q", a, "Bearer secret")
+		}
+	}
+}
+
+func signalsEqual(a, b Signals) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for metric, sa := range a {
+		sb, ok := b[metric]
+		if !ok || sa.Value != sb.Value || sa.Source != sb.Source || sa.Samples != sb.Samples || !sa.ObservedAt.Equal(sb.ObservedAt) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPrometheusCollector_QueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	}))
+	defer server.Close()
+
+	c, err := NewPrometheusCollector(PrometheusConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+	if _, err := c.CollectSignals(context.TODO(), "worker-1"); err == nil {
+		t.Error("CollectSignals() expected error, got nil")
+	}
+}
+
+func TestDefaultPrometheusQueries_Render(t *testing.T) {
+	c, err := NewPrometheusCollector(PrometheusConfig{
+		URL:            "http://prometheus:9090",
+		InstanceMapper: FormatInstanceMapper("%s.internal"),
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+	for metric, tmpl := range c.queries {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, QueryParams{Node: "worker-1", Instance: "worker-1.internal"}); err != nil {
+			t.Errorf("failed to render %s: %v", metric, err)
+		}
+		if metric != scorer.MetricKubeletErrors && !strings.Contains(b.String(), `worker-1\\.internal`) {
+			t.Errorf("query for %s does not escape the instance: %s", metric, b.String())
+		}
+	}
+}
+
+func TestPrometheusCollector_QueriesPerCollection(t *testing.T) {
+	c, err := NewPrometheusCollector(PrometheusConfig{
+		URL:     "http://prometheus:9090",
+		Queries: map[scorer.MetricName]string{scorer.MetricDiskIOWait: `io`},
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+	// The default pool queries outnumber the single per-node query.
+	if got, want := c.QueriesPerCollection(), len(DefaultPrometheusPoolQueries); got != want {
+		t.Errorf("QueriesPerCollection() = %d, want %d", got, want)
+	}
+}
+
+func TestPrometheusCollector_CollectPoolSignals(t *testing.T) {
+	fake := &fakePrometheus{
+		t: t,
+		results: map[string]string{
+			`io_by_instance`: `{"resultType":"vector","result":[
+				{"metric":{"instance":"10.0.0.1:9100"},"value":[1700000000,"0.4"]},
+				{"metric":{"instance":"10.0.0.2:9100"},"value":[1700000000,"0.9"]},
+				{"metric":{"instance":"10.0.0.99:9100"},"value":[1700000000,"1"]}]}`,
+			`errors_by_node`: `{"resultType":"vector","result":[
+				{"metric":{"node":"worker-2"},"value":[1700000000,"0.3"]}]}`,
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	c, err := NewPrometheusCollector(PrometheusConfig{
+		URL: server.URL,
+		PoolQueries: map[scorer.MetricName]PoolQuery{
+			scorer.MetricDiskIOWait:    {Query: `io_by_instance`, Label: "instance"},
+			scorer.MetricKubeletErrors: {Query: `errors_by_node`, Label: "node"},
+		},
+		InstanceMapper: StaticInstanceMapper(map[string]string{
+			"worker-1": "10.0.0.1",
+			"worker-2": "10.0.0.2",
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+
+	got, err := c.CollectPoolSignals(context.TODO(), []string{"worker-1", "worker-2", "worker-3"})
+	if err != nil {
+		t.Fatalf("CollectPoolSignals() error = %v", err)
+	}
+	if len(fake.queries) != 2 {
+		t.Errorf("expected one query per metric, got %d", len(fake.queries))
+	}
+
+	want := map[string]map[scorer.MetricName]float64{
+		"worker-1": {scorer.MetricDiskIOWait: 0.4},
+		"worker-2": {scorer.MetricDiskIOWait: 0.9, scorer.MetricKubeletErrors: 0.3},
+		"worker-3": {},
+	}
+	for node, signals := range got {
+		if !reflect.DeepEqual(signals.Values(), want[node]) {
+			t.Errorf("CollectPoolSignals()[%s] = %v, want %v", node, signals.Values(), want[node])
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("CollectPoolSignals() returned %d nodes, want %d", len(got), len(want))
+	}
+}
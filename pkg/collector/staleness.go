@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// StalenessPolicy decides which signals are fresh enough to be trusted.
+type StalenessPolicy struct {
+	// MaxAge is the oldest a signal may be. Zero disables the age check.
+	MaxAge time.Duration
+
+	// MaxAgeByMetric overrides MaxAge for individual metrics.
+	MaxAgeByMetric map[scorer.MetricName]time.Duration
+
+	// MinSamples is the fewest samples a signal for the metric must be based on.
+	// It is set per metric because for event-derived signals zero samples is a valid reading.
+	MinSamples map[scorer.MetricName]int
+}
+
+// Evaluate splits the signals into trusted values and unknown metrics. Every expected metric that
+// is missing, too old or based on too few samples is reported as unknown, so that a dead exporter
+// is never mistaken for a healthy 0.0.
+func (p StalenessPolicy) Evaluate(now time.Time, signals Signals, expected []scorer.MetricName) (map[scorer.MetricName]float64, []scorer.MetricName) {
+	values := make(map[scorer.MetricName]float64, len(signals))
+	unknown := make(map[scorer.MetricName]bool)
+	for metric, sig := range signals {
+		if p.isStale(now, metric, sig) {
+			unknown[metric] = true
+			continue
+		}
+		values[metric] = sig.Value
+	}
+	for _, metric := range expected {
+		if _, ok := values[metric]; !ok {
+			unknown[metric] = true
+		}
+	}
+
+	names := make([]scorer.MetricName, 0, len(unknown))
+	for metric := range unknown {
+		names = append(names, metric)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return values, names
+}
+
+func (p StalenessPolicy) isStale(now time.Time, metric scorer.MetricName, sig Signal) bool {
+	if min, ok := p.MinSamples[metric]; ok && sig.Samples < min {
+		return true
+	}
+	maxAge := p.MaxAge
+	if age, ok := p.MaxAgeByMetric[metric]; ok {
+		maxAge = age
+	}
+	if maxAge <= 0 {
+		return false
+	}
+	return sig.ObservedAt.IsZero() || now.Sub(sig.ObservedAt) > maxAge
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+func TestStalenessPolicy_Evaluate(t *testing.T) {
+	now := time.Now()
+	policy := StalenessPolicy{
+		MaxAge:         5 * time.Minute,
+		MaxAgeByMetric: map[scorer.MetricName]time.Duration{scorer.MetricConditionFlaps: time.Hour},
+		MinSamples:     map[scorer.MetricName]int{scorer.MetricNetworkDrops: 2},
+	}
+	signals := Signals{
+		scorer.MetricDiskIOWait:     {Value: 0.2, ObservedAt: now.Add(-time.Minute), Samples: 1},
+		scorer.MetricMemoryPressure: {Value: 0.0, ObservedAt: now.Add(-10 * time.Minute), Samples: 1},
+		scorer.MetricConditionFlaps: {Value: 0.1, ObservedAt: now.Add(-10 * time.Minute), Samples: 1},
+		scorer.MetricNetworkDrops:   {Value: 0.0, ObservedAt: now, Samples: 1},
+	}
+
+	values, unknown := policy.Evaluate(now, signals, []scorer.MetricName{scorer.MetricDiskIOWait, scorer.MetricKubeletErrors})
+
+	wantValues := map[scorer.MetricName]float64{scorer.MetricDiskIOWait: 0.2, scorer.MetricConditionFlaps: 0.1}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("values = %v, want %v", values, wantValues)
+	}
+	// A stale 0.0 or a missing exporter must surface as unknown, not as healthy.
+	wantUnknown := []scorer.MetricName{scorer.MetricKubeletErrors, scorer.MetricMemoryPressure, scorer.MetricNetworkDrops}
+	if !reflect.DeepEqual(unknown, wantUnknown) {
+		t.Errorf("unknown = %v, want %v", unknown, wantUnknown)
+	}
+}
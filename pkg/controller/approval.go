@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// defaultApprovalTTL is how long a NodeRemediation waits for approval when the policy does not say.
+const defaultApprovalTTL = time.Hour
+
+// requiresApproval reports whether the policy's remediations wait for a user's approval.
+func requiresApproval(policy *v1alpha1.NodeHealingPolicy) bool {
+	return policy.Spec.Remediation.Approval == v1alpha1.ApprovalRequired
+}
+
+// approvalTTL returns how long the policy's remediations wait for approval.
+func approvalTTL(policy *v1alpha1.NodeHealingPolicy) time.Duration {
+	if ttl := policy.Spec.Remediation.ApprovalTTL.Duration; ttl > 0 {
+		return ttl
+	}
+	return defaultApprovalTTL
+}
+
+// expiresAt returns when the remediation expires unless approved, or once approved unless started.
+// Requests whose status was never written expire one TTL after their creation.
+func expiresAt(policy *v1alpha1.NodeHealingPolicy, nr *v1alpha1.NodeRemediation) time.Time {
+	if nr.Status.ExpiresAt != nil {
+		return nr.Status.ExpiresAt.Time
+	}
+	return nr.CreationTimestamp.Add(approvalTTL(policy))
+}
+
+// isApproved reports whether a user approved the remediation.
+func isApproved(nr *v1alpha1.NodeRemediation) bool {
+	return nr.Spec.Approved || nr.Annotations[v1alpha1.AnnotationApproved] == "true"
+}
+
+// isOpen reports whether the remediation still waits for approval or, once approved, to start.
+func isOpen(nr *v1alpha1.NodeRemediation) bool {
+	phase := nr.Status.Phase
+	return phase == "" || phase == v1alpha1.NodeRemediationPendingApproval || phase == v1alpha1.NodeRemediationApproved
+}
+
+// approved returns the NodeRemediation approving the action on the node, or "" and the reason to
+// wait. Without one it requests approval with a new NodeRemediation unless one is already pending.
+// Open requests for another action are superseded, and requests expire after the policy's approval
+// TTL: unapproved ones when it passes after they were proposed, approved ones when it passes after
+// the approval without the remediation starting. An approval is used up by the remediation it
+// starts, see startRemediation, so every remediation needs an approval of its own.
+func (r *NodeHealthReconciler) approved(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, action, reason string, score float64, breakdown map[scorer.MetricName]float64, now time.Time) (string, string, error) {
+	list, err := r.openApprovals(ctx, policy, node.Name)
+	if err != nil {
+		return "", "", err
+	}
+
+	var pending *v1alpha1.NodeRemediation
+	for i := range list {
+		nr := &list[i]
+		switch {
+		case nr.Spec.Action != action:
+			if err := r.setApprovalPhase(ctx, nr, v1alpha1.NodeRemediationSuperseded, fmt.Sprintf("Superseded by a proposal to %s", action)); err != nil {
+				return "", "", err
+			}
+		case !now.Before(expiresAt(policy, nr)):
+			message := "Not approved in time"
+			if nr.Status.Phase == v1alpha1.NodeRemediationApproved {
+				message = "Approved but not started in time"
+			}
+			if err := r.setApprovalPhase(ctx, nr, v1alpha1.NodeRemediationExpired, message); err != nil {
+				return "", "", err
+			}
+		case nr.Status.Phase == v1alpha1.NodeRemediationApproved:
+			return nr.Name, "", nil
+		case isApproved(nr):
+			// The approval holds for one TTL, e.g. while other nodes hold the drain slots.
+			base := nr.DeepCopy()
+			nr.Status.Phase = v1alpha1.NodeRemediationApproved
+			nr.Status.ExpiresAt = &metav1.Time{Time: now.Add(approvalTTL(policy))}
+			nr.Status.Message = "Approved, starts once the policy's limits allow"
+			if err := r.Status().Patch(ctx, nr, client.MergeFrom(base)); err != nil {
+				return "", "", fmt.Errorf("failed to update status of remediation %s: %w", nr.Name, err)
+			}
+			return nr.Name, "", nil
+		default:
+			pending = nr
+		}
+	}
+	if pending != nil {
+		return "", fmt.Sprintf("NodeRemediation %s is pending approval", pending.Name), nil
+	}
+
+	nr, err := r.requestApproval(ctx, policy, node, action, reason, score, breakdown, now)
+	if err != nil {
+		return "", "", err
+	}
+	return "", fmt.Sprintf("approval was requested with NodeRemediation %s", nr.Name), nil
+}
+
+// openApprovals returns the policy's NodeRemediations of the node that wait for approval or to start.
+func (r *NodeHealthReconciler) openApprovals(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, nodeName string) ([]v1alpha1.NodeRemediation, error) {
+	var list v1alpha1.NodeRemediationList
+	if err := r.List(ctx, &list, client.MatchingLabels{v1alpha1.LabelNodeName: nodeName}); err != nil {
+		return nil, fmt.Errorf("failed to list remediations of node %s: %w", nodeName, err)
+	}
+	open := list.Items[:0]
+	for _, nr := range list.Items {
+		if nr.Spec.PolicyName == policy.Name && isOpen(&nr) {
+			open = append(open, nr)
+		}
+	}
+	return open, nil
+}
+
+// withdrawApprovals supersedes the open NodeRemediations of a node that recovered, so an approval
+// given for an earlier episode cannot authorize a later, unrelated remediation.
+func (r *NodeHealthReconciler) withdrawApprovals(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, nodeName string) error {
+	list, err := r.openApprovals(ctx, policy, nodeName)
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if err := r.setApprovalPhase(ctx, &list[i], v1alpha1.NodeRemediationSuperseded, "Withdrawn, the node recovered"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useApproval marks the approval as used by the remediation that started.
+func (r *NodeHealthReconciler) useApproval(ctx context.Context, name string) error {
+	var nr v1alpha1.NodeRemediation
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, &nr); err != nil {
+		return fmt.Errorf("failed to get remediation %s: %w", name, err)
+	}
+	return r.setApprovalPhase(ctx, &nr, v1alpha1.NodeRemediationInProgress, "Remediating")
+}
+
+// requestApproval creates a NodeRemediation pending approval. It is owned by the policy, so
+// deleting the policy cleans up its requests.
+func (r *NodeHealthReconciler) requestApproval(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, action, reason string, score float64, breakdown map[scorer.MetricName]float64, now time.Time) (*v1alpha1.NodeRemediation, error) {
+	nr := &v1alpha1.NodeRemediation{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: node.Name + "-",
+			Labels:       map[string]string{v1alpha1.LabelNodeName: node.Name},
+		},
+		Spec: v1alpha1.NodeRemediationSpec{
+			NodeName:       node.Name,
+			PolicyName:     policy.Name,
+			Action:         action,
+			Reason:         reason,
+			Score:          score,
+			ScoreBreakdown: make(map[string]float64, len(breakdown)),
+		},
+	}
+	for metric, contribution := range breakdown {
+		nr.Spec.ScoreBreakdown[string(metric)] = contribution
+	}
+	if err := controllerutil.SetOwnerReference(policy, nr, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner of remediation for node %s: %w", node.Name, err)
+	}
+	if err := r.Create(ctx, nr); err != nil {
+		return nil, fmt.Errorf("failed to request approval for node %s: %w", node.Name, err)
+	}
+
+	base := nr.DeepCopy()
+	expires := metav1.NewTime(now.Add(approvalTTL(policy)))
+	nr.Status = v1alpha1.NodeRemediationStatus{
+		Phase:     v1alpha1.NodeRemediationPendingApproval,
+		ExpiresAt: &expires,
+		Message:   "Waiting for approval",
+	}
+	if err := r.Status().Patch(ctx, nr, client.MergeFrom(base)); err != nil {
+		return nil, fmt.Errorf("failed to update status of remediation %s: %w", nr.Name, err)
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(node, corev1.EventTypeNormal, "ApprovalRequested",
+			fmt.Sprintf("Approval to %s the node was requested with NodeRemediation %s: %s", action, nr.Name, reason))
+	}
+	return nr, nil
+}
+
+// completeApproval records the outcome of the remediation on the NodeRemediation that approved it.
+// Failures are only logged, like those of finishRemediation.
+func (r *NodeHealthReconciler) completeApproval(ctx context.Context, rec v1alpha1.RemediationRecord) {
+	if rec.Approval == "" {
+		return
+	}
+	phase, message := v1alpha1.NodeRemediationSucceeded, "Remediated"
+	if rec.Outcome == v1alpha1.RemediationFailed {
+		phase, message = v1alpha1.NodeRemediationFailed, rec.Message
+	}
+	var nr v1alpha1.NodeRemediation
+	if err := r.Get(ctx, client.ObjectKey{Name: rec.Approval}, &nr); err != nil {
+		r.Log.Error(err, "failed to get remediation", "remediation", rec.Approval)
+		return
+	}
+	if err := r.setApprovalPhase(ctx, &nr, phase, message); err != nil {
+		r.Log.Error(err, "failed to record remediation outcome", "remediation", nr.Name)
+	}
+}
+
+// setApprovalPhase moves the NodeRemediation to the phase.
+func (r *NodeHealthReconciler) setApprovalPhase(ctx context.Context, nr *v1alpha1.NodeRemediation, phase v1alpha1.NodeRemediationPhase, message string) error {
+	base := nr.DeepCopy()
+	nr.Status.Phase = phase
+	nr.Status.Message = message
+	if err := r.Status().Patch(ctx, nr, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to update status of remediation %s: %w", nr.Name, err)
+	}
+	return nil
+}
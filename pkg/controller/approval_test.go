@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+func TestApproved(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.Remediation.Approval = v1alpha1.ApprovalRequired
+		p.Spec.Remediation.ApprovalTTL = metav1.Duration{Duration: time.Hour}
+	})
+	node := testNode("worker-1", nil)
+	request := func(name, action string, phase v1alpha1.NodeRemediationPhase, expires time.Time, approved bool) *v1alpha1.NodeRemediation {
+		return &v1alpha1.NodeRemediation{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1alpha1.LabelNodeName: node.Name}},
+			Spec:       v1alpha1.NodeRemediationSpec{NodeName: node.Name, PolicyName: policy.Name, Action: action, Approved: approved},
+			Status:     v1alpha1.NodeRemediationStatus{Phase: phase, ExpiresAt: &metav1.Time{Time: expires}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		existing     *v1alpha1.NodeRemediation
+		wantApproval string
+		wantPhase    v1alpha1.NodeRemediationPhase
+		wantRequests int
+	}{
+		{
+			name:         "requests approval",
+			wantRequests: 1,
+		},
+		{
+			name:         "pending",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationPendingApproval, now.Add(time.Minute), false),
+			wantPhase:    v1alpha1.NodeRemediationPendingApproval,
+			wantRequests: 1,
+		},
+		{
+			name:         "approved by a user",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationPendingApproval, now.Add(time.Minute), true),
+			wantApproval: "worker-1-abc",
+			wantPhase:    v1alpha1.NodeRemediationApproved,
+			wantRequests: 1,
+		},
+		{
+			name:         "approved and waiting to start",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationApproved, now.Add(time.Minute), true),
+			wantApproval: "worker-1-abc",
+			wantPhase:    v1alpha1.NodeRemediationApproved,
+			wantRequests: 1,
+		},
+		{
+			name:         "pending expired",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationPendingApproval, now.Add(-time.Minute), false),
+			wantPhase:    v1alpha1.NodeRemediationExpired,
+			wantRequests: 2,
+		},
+		{
+			name:         "approved expired",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationApproved, now.Add(-time.Minute), true),
+			wantPhase:    v1alpha1.NodeRemediationExpired,
+			wantRequests: 2,
+		},
+		{
+			name:         "superseded by another action",
+			existing:     request("worker-1-abc", "Reboot", v1alpha1.NodeRemediationApproved, now.Add(time.Minute), true),
+			wantPhase:    v1alpha1.NodeRemediationSuperseded,
+			wantRequests: 2,
+		},
+		{
+			name:         "used up",
+			existing:     request("worker-1-abc", "Drain", v1alpha1.NodeRemediationSucceeded, now.Add(time.Minute), true),
+			wantPhase:    v1alpha1.NodeRemediationSucceeded,
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{policy.DeepCopy(), node.DeepCopy()}
+			if tt.existing != nil {
+				objs = append(objs, tt.existing.DeepCopy())
+			}
+			c := newFakeClient(objs...)
+			r := &NodeHealthReconciler{Client: c, Scheme: c.Scheme()}
+
+			approval, wait, err := r.approved(ctx, policy, node, "Drain", "Health score 0.80 exceeds threshold 0.60", 0.8, nil, now)
+			if err != nil {
+				t.Fatalf("approved() error = %v", err)
+			}
+			if approval != tt.wantApproval {
+				t.Errorf("approved() = %q (%s), want %q", approval, wait, tt.wantApproval)
+			}
+			if approval == "" && wait == "" {
+				t.Errorf("approved() gave no reason to wait")
+			}
+
+			var list v1alpha1.NodeRemediationList
+			if err := c.List(ctx, &list); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(list.Items) != tt.wantRequests {
+				t.Errorf("%d NodeRemediations, want %d", len(list.Items), tt.wantRequests)
+			}
+			for _, nr := range list.Items {
+				if tt.existing != nil && nr.Name == tt.existing.Name {
+					if nr.Status.Phase != tt.wantPhase {
+						t.Errorf("phase of %s = %s, want %s", nr.Name, nr.Status.Phase, tt.wantPhase)
+					}
+					continue
+				}
+				if nr.Status.Phase != v1alpha1.NodeRemediationPendingApproval || !nr.Status.ExpiresAt.Time.Equal(now.Add(time.Hour)) {
+					t.Errorf("new request %s = %+v, want pending until %s", nr.Name, nr.Status, now.Add(time.Hour))
+				}
+			}
+		})
+	}
+}
+
+func TestApprovalLifecycle(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.Remediation.Approval = v1alpha1.ApprovalRequired
+	})
+	node := testNode("worker-1", nil)
+	c := newFakeClient(policy, node)
+	r := &NodeHealthReconciler{Client: c, Scheme: c.Scheme()}
+	r.Remediator = &remediation.Executor{Client: c}
+
+	phase := func(name string) v1alpha1.NodeRemediationPhase {
+		var nr v1alpha1.NodeRemediation
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, &nr); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return nr.Status.Phase
+	}
+	approve := func() string {
+		var list v1alpha1.NodeRemediationList
+		if err := c.List(ctx, &list); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, nr := range list.Items {
+			if nr.Status.Phase == v1alpha1.NodeRemediationPendingApproval {
+				nr.Spec.Approved = true
+				if err := c.Update(ctx, &nr); err != nil {
+					t.Fatalf("Update() error = %v", err)
+				}
+				return nr.Name
+			}
+		}
+		t.Fatalf("no pending NodeRemediation")
+		return ""
+	}
+
+	// A request approved for one remediation is used up by it.
+	if _, _, err := r.approved(ctx, policy, node, "Drain", "unhealthy", 0.8, nil, now); err != nil {
+		t.Fatalf("approved() error = %v", err)
+	}
+	first := approve()
+	approval, _, err := r.approved(ctx, policy, node, "Drain", "unhealthy", 0.8, nil, now)
+	if err != nil || approval != first {
+		t.Fatalf("approved() = %q, %v, want %q", approval, err, first)
+	}
+	rec, wait, err := r.startRemediation(ctx, policy, node, "Drain", "unhealthy", approval, now)
+	if err != nil || rec == nil {
+		t.Fatalf("startRemediation() = %v, %q, %v", rec, wait, err)
+	}
+	if got := phase(first); got != v1alpha1.NodeRemediationInProgress {
+		t.Errorf("phase after start = %s, want InProgress", got)
+	}
+	r.finishRemediation(ctx, policy, *rec, nil)
+	if got := phase(first); got != v1alpha1.NodeRemediationSucceeded {
+		t.Errorf("phase after finish = %s, want Succeeded", got)
+	}
+
+	// The next remediation needs a new approval.
+	approval, _, err = r.approved(ctx, policy, node, "Drain", "unhealthy again", 0.8, nil, now.Add(time.Hour))
+	if err != nil || approval != "" {
+		t.Fatalf("approved() = %q, %v, want a new request", approval, err)
+	}
+
+	// Recovery withdraws it, approved or not.
+	second := approve()
+	if err := r.withdrawApprovals(ctx, policy, node.Name); err != nil {
+		t.Fatalf("withdrawApprovals() error = %v", err)
+	}
+	if got := phase(second); got != v1alpha1.NodeRemediationSuperseded {
+		t.Errorf("phase after recovery = %s, want Superseded", got)
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// newFakeClient returns a fake client serving the given objects with the status subresources
+// and field indexes the controllers rely on.
+func newFakeClient(objs ...client.Object) client.WithWatch {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	return ctrlfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&corev1.Node{}, &v1alpha1.NodeHealingPolicy{}, &v1alpha1.NodeRemediation{}).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithObjects(objs...).
+		Build()
+}
+
+// testNode returns a Ready node of pool-a.
+func testNode(name string, mutate func(*corev1.Node)) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "a"}},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-" + name},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	if mutate != nil {
+		mutate(node)
+	}
+	return node
+}
+
+// testPolicy returns an enforcing policy selecting the nodes of pool-a.
+func testPolicy(mutate func(*v1alpha1.NodeHealingPolicy)) *v1alpha1.NodeHealingPolicy {
+	policy := &v1alpha1.NodeHealingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-a", UID: "uid-pool-a"},
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			NodeSelector: map[string]string{"pool": "a"},
+			PoolID:       "pool-a",
+			Thresholds:   v1alpha1.Thresholds{UnhealthyScore: 0.6},
+			Mode:         v1alpha1.PolicyEnforce,
+		},
+	}
+	if mutate != nil {
+		mutate(policy)
+	}
+	return policy
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// lastRemediation returns when any node covered by the policy was last remediated. The record
+// lives in the policy status, so the cooldown survives controller restarts and replaced nodes,
+// and only counts the nodes the policy takes precedence on.
+func lastRemediation(policy *v1alpha1.NodeHealingPolicy) time.Time {
+	if rec := policy.Status.LastRemediation; rec != nil {
+		return rec.Time.Time
+	}
+	return time.Time{}
+}
+
+// recordRemediation persists the record on the node and on the policy.
+func (r *NodeHealthReconciler) recordRemediation(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord) error {
+	if err := r.Remediator.RecordRemediation(ctx, rec); err != nil {
+		return err
+	}
+	return r.recordPolicyRemediation(ctx, policy, rec)
+}
+
+// finishRemediation records the outcome of a remediation started with startRemediation, frees
+// its drain slot and completes its approval. A successful ladder step is recorded on the node.
+// Failures are only logged; the attempt itself is already recorded and a slot whose
+// remediation finished is reclaimed by the next acquisition.
+func (r *NodeHealthReconciler) finishRemediation(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord, err error) {
+	rec.Outcome = v1alpha1.RemediationSucceeded
+	if err != nil {
+		rec.Outcome = v1alpha1.RemediationFailed
+		rec.Message = err.Error()
+		rec.Phase, rec.PhaseTime = v1alpha1.PhaseFailed, &metav1.Time{Time: time.Now()}
+	}
+	if err := r.recordRemediation(ctx, policy, rec); err != nil {
+		r.Log.Error(err, "failed to record remediation outcome", "node", rec.Node)
+	}
+	if err := r.releaseDrainSlot(ctx, policy, rec.Node); err != nil {
+		r.Log.Error(err, "failed to release drain slot", "node", rec.Node)
+	}
+	r.completeApproval(ctx, rec)
+	if err == nil {
+		r.completeLadderStep(ctx, policy, rec)
+	}
+}
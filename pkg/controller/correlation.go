@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// analyzeCorrelations looks for attributes shared by the pool's unhealthy nodes, records the
+// findings on the policy status, emits an event for each new finding and returns the findings
+// the node is part of. A node counts as unhealthy if its latest score is recent and at or above
+// the policy's threshold.
+func (r *NodeHealthReconciler) analyzeCorrelations(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, now time.Time) ([]v1alpha1.CorrelationFinding, error) {
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes, client.MatchingLabels(policy.Spec.NodeSelector)); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	fresh := now.Add(-2 * r.evaluationInterval(policy))
+	unhealthy := make(map[string]bool)
+	for i := range nodes.Items {
+		s, ok := r.History.Latest(nodes.Items[i].Name)
+		if ok && s.At.After(fresh) && s.Score >= policy.Spec.Thresholds.UnhealthyScore {
+			unhealthy[nodes.Items[i].Name] = true
+		}
+	}
+	findings := r.Correlation.Analyze(nodes.Items, unhealthy)
+
+	previous := policy.Status.Correlations
+	err := r.patchPolicyStatus(ctx, policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+		if reflect.DeepEqual(status.Correlations, findings) {
+			return false
+		}
+		status.Correlations = findings
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.Recorder != nil {
+		for _, f := range findings {
+			if !hasFinding(previous, f) {
+				r.Recorder.Eventf(policy, corev1.EventTypeWarning, "CorrelatedDegradation",
+					"%d of %d unhealthy nodes share %s=%s (%d nodes have it)", f.UnhealthyNodes, f.TotalUnhealthy, f.Attribute, f.Value, f.Nodes)
+			}
+		}
+	}
+	return r.Correlation.FindingsFor(node, findings), nil
+}
+
+func hasFinding(findings []v1alpha1.CorrelationFinding, f v1alpha1.CorrelationFinding) bool {
+	for _, existing := range findings {
+		if existing.Attribute == f.Attribute && existing.Value == f.Value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+// staleDrainGrace is added to the phase timeout before an in-progress remediation that stopped
+// making progress, e.g. because its policy was disabled, stops holding its drain slot.
+const staleDrainGrace = 5 * time.Minute
+
+// maxConcurrentDrains returns the policy's drain limit, defaulting to 1.
+func maxConcurrentDrains(policy *v1alpha1.NodeHealingPolicy) int {
+	if policy.Spec.Limits.MaxConcurrentDrains > 0 {
+		return policy.Spec.Limits.MaxConcurrentDrains
+	}
+	return 1
+}
+
+// activeDrains returns the nodes of the policy that still hold a drain slot.
+// Entries of deleted nodes, finished remediations and remediations stuck in a phase beyond its
+// timeout are dropped. A replaced node holds its slot until its replacement is Ready, even once
+// the node itself is gone.
+func (r *NodeHealthReconciler) activeDrains(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, now time.Time) ([]string, error) {
+	active := make([]string, 0, len(policy.Status.ActiveRemediations))
+	for _, name := range policy.Status.ActiveRemediations {
+		rec := replacing(policy, name)
+		var node corev1.Node
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, &node); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get node %s: %w", name, err)
+			}
+		} else if nodeRec, err := remediation.LastRemediation(&node); err == nil && nodeRec != nil {
+			rec = nodeRec
+		}
+		if rec == nil || rec.Outcome != v1alpha1.RemediationInProgress {
+			continue
+		}
+		if now.Sub(phaseStart(*rec)) > phaseTimeout(policy, rec.Phase)+staleDrainGrace {
+			continue
+		}
+		active = append(active, name)
+	}
+	return active, nil
+}
+
+// acquireDrainSlot records the node as an active remediation of the policy if fewer than
+// MaxConcurrentDrains are in progress and the pool disruption budget allows it. The status is
+// patched with an optimistic lock so concurrent acquisitions cannot exceed the limits.
+// If no slot is available it returns false and the reason.
+//
+// The caller must hold drainMu and record the node's remediation as in progress before
+// releasing it, otherwise the slot is considered stale by the next acquisition.
+func (r *NodeHealthReconciler) acquireDrainSlot(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, now time.Time) (bool, string, error) {
+	var acquired bool
+	var reason string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest v1alpha1.NodeHealingPolicy
+		if err := r.Get(ctx, client.ObjectKeyFromObject(policy), &latest); err != nil {
+			return err
+		}
+		active, err := r.activeDrains(ctx, &latest, now)
+		if err != nil {
+			return err
+		}
+		active, reason, err = r.drainSlot(ctx, &latest, node, active)
+		if err != nil {
+			return err
+		}
+		if acquired = reason == ""; !acquired {
+			return nil
+		}
+
+		base := latest.DeepCopy()
+		latest.Status.ActiveRemediations = active
+		if err := r.Status().Patch(ctx, &latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		policy.Status = latest.Status
+		return nil
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to acquire drain slot for node %s: %w", node.Name, err)
+	}
+	return acquired, reason, nil
+}
+
+// drainSlot returns the active drains with the node added if it may take a drain slot, or the
+// reason it may not: the policy's MaxConcurrentDrains are in progress or the pool disruption
+// budget is exhausted. A node already holding a slot keeps it.
+func (r *NodeHealthReconciler) drainSlot(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, active []string) ([]string, string, error) {
+	for _, name := range active {
+		if name == node.Name {
+			return active, "", nil
+		}
+	}
+	if limit := maxConcurrentDrains(policy); len(active) >= limit {
+		return nil, fmt.Sprintf("%d of %d concurrent drains are in progress", len(active), limit), nil
+	}
+	// Measured against the active drains rather than the decision's copy, so drains started
+	// since the decision count.
+	measured := policy.DeepCopy()
+	measured.Status.ActiveRemediations = active
+	budget, err := r.poolBudget(ctx, measured, node)
+	if err != nil {
+		return nil, "", err
+	}
+	if budget != nil {
+		if ok, why := budget.Allows(policy.Spec.Limits); !ok {
+			return nil, fmt.Sprintf("the pool disruption budget is exhausted: %s", why), nil
+		}
+	}
+	return append(active, node.Name), "", nil
+}
+
+// releaseDrainSlot removes the node from the policy's active remediations.
+func (r *NodeHealthReconciler) releaseDrainSlot(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, nodeName string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest v1alpha1.NodeHealingPolicy
+		if err := r.Get(ctx, client.ObjectKeyFromObject(policy), &latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		active := make([]string, 0, len(latest.Status.ActiveRemediations))
+		for _, name := range latest.Status.ActiveRemediations {
+			if name != nodeName {
+				active = append(active, name)
+			}
+		}
+		if len(active) == len(latest.Status.ActiveRemediations) {
+			return nil
+		}
+		base := latest.DeepCopy()
+		latest.Status.ActiveRemediations = active
+		if err := r.Status().Patch(ctx, &latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		policy.Status = latest.Status
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release drain slot of node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// startRemediation acquires a drain slot for the node and records the remediation as pending,
+// starting the cooldown and using up the approval, if any. advanceRemediation then takes it
+// through its workflow. If no slot is available it returns a nil record and the reason.
+func (r *NodeHealthReconciler) startRemediation(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, action, reason, approval string, now time.Time) (*v1alpha1.RemediationRecord, string, error) {
+	r.drainMu.Lock()
+	defer r.drainMu.Unlock()
+
+	acquired, waitReason, err := r.acquireDrainSlot(ctx, policy, node, now)
+	if err != nil || !acquired {
+		return nil, waitReason, err
+	}
+	rec := v1alpha1.RemediationRecord{
+		Node:      node.Name,
+		Action:    action,
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(now),
+		Message:   reason,
+		Phase:     v1alpha1.PhasePending,
+		PhaseTime: &metav1.Time{Time: now},
+		Approval:  approval,
+	}
+	if err := r.recordRemediation(ctx, policy, rec); err != nil {
+		if releaseErr := r.releaseDrainSlot(ctx, policy, node.Name); releaseErr != nil {
+			r.Log.Error(releaseErr, "failed to release drain slot", "node", node.Name)
+		}
+		return nil, "", err
+	}
+	if approval != "" {
+		if err := r.useApproval(ctx, approval); err != nil {
+			r.Log.Error(err, "failed to mark approval as used", "node", node.Name)
+		}
+	}
+	return &rec, "", nil
+}
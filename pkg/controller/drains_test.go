@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+// drainingNode returns a node of pool-a whose remediation entered the phase at the given time.
+func drainingNode(t *testing.T, name string, outcome v1alpha1.RemediationOutcome, since time.Time) *corev1.Node {
+	t.Helper()
+	value, err := json.Marshal(v1alpha1.RemediationRecord{
+		Node:      name,
+		Action:    string(v1alpha1.RemediationReboot),
+		Outcome:   outcome,
+		Time:      metav1.NewTime(since),
+		Phase:     v1alpha1.PhaseDraining,
+		PhaseTime: &metav1.Time{Time: since},
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return testNode(name, func(n *corev1.Node) {
+		n.Annotations = map[string]string{remediation.AnnotationLastRemediation: string(value)}
+	})
+}
+
+func TestActiveDrains(t *testing.T) {
+	now := time.Now()
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Status.ActiveRemediations = []string{"draining", "deleted", "finished", "stale", "replaced"}
+		p.Status.Replacements = []v1alpha1.ReplacementRecord{
+			{Node: "replaced", Remediation: replacingRecord("replaced", now.Add(-time.Minute))},
+		}
+	})
+	c := newFakeClient(policy,
+		drainingNode(t, "draining", v1alpha1.RemediationInProgress, now.Add(-time.Minute)),
+		drainingNode(t, "finished", v1alpha1.RemediationSucceeded, now.Add(-time.Minute)),
+		drainingNode(t, "stale", v1alpha1.RemediationInProgress, now.Add(-remediation.DefaultDrainTimeout-staleDrainGrace-time.Minute)),
+	)
+	r := newWorkflowReconciler(c, nil)
+
+	got, err := r.activeDrains(context.TODO(), policy, now)
+	if err != nil {
+		t.Fatalf("activeDrains() error = %v", err)
+	}
+	// A deleted node only keeps its slot while its replacement is awaited.
+	if want := []string{"draining", "replaced"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("activeDrains() = %v, want %v", got, want)
+	}
+}
+
+func TestStartRemediation_MaxConcurrentDrains(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.Limits.MaxConcurrentDrains = 2
+	})
+	nodes := []*corev1.Node{testNode("worker-1", nil), testNode("worker-2", nil), testNode("worker-3", nil)}
+	c := newFakeClient(policy, nodes[0], nodes[1], nodes[2])
+	r := newWorkflowReconciler(c, nil)
+
+	for _, node := range nodes[:2] {
+		if rec, reason, err := r.startRemediation(ctx, policy, node, string(v1alpha1.RemediationReboot), "unhealthy", "", now); err != nil || rec == nil {
+			t.Fatalf("startRemediation(%s) = %v, %q, %v, want a record", node.Name, rec, reason, err)
+		}
+	}
+	rec, reason, err := r.startRemediation(ctx, policy, nodes[2], string(v1alpha1.RemediationReboot), "unhealthy", "", now)
+	if err != nil {
+		t.Fatalf("startRemediation() error = %v", err)
+	}
+	if rec != nil || reason != "2 of 2 concurrent drains are in progress" {
+		t.Errorf("startRemediation(worker-3) = %v, %q, want no record and the drain limit as reason", rec, reason)
+	}
+
+	// A node holding a slot keeps it.
+	if acquired, _, err := r.acquireDrainSlot(ctx, policy, nodes[0], now); err != nil || !acquired {
+		t.Errorf("acquireDrainSlot(worker-1) = %v, %v, want the slot it holds", acquired, err)
+	}
+	if got, want := getPolicy(t, c, policy.Name).Status.ActiveRemediations, []string{"worker-1", "worker-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ActiveRemediations = %v, want %v", got, want)
+	}
+}
+
+func TestStartRemediation_SlotFreedOnFinish(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	policy := testPolicy(nil)
+	first, second := testNode("worker-1", nil), testNode("worker-2", nil)
+	c := newFakeClient(policy, first, second)
+	r := newWorkflowReconciler(c, nil)
+
+	rec, _, err := r.startRemediation(ctx, policy, first, string(v1alpha1.RemediationReboot), "unhealthy", "", now)
+	if err != nil || rec == nil {
+		t.Fatalf("startRemediation(worker-1) = %v, %v, want a record", rec, err)
+	}
+	if rec, reason, err := r.startRemediation(ctx, policy, second, string(v1alpha1.RemediationReboot), "unhealthy", "", now); err != nil || rec != nil {
+		t.Fatalf("startRemediation(worker-2) = %v, %q, %v, want to wait for the slot", rec, reason, err)
+	}
+
+	r.finishRemediation(ctx, policy, *rec, nil)
+	if got := getPolicy(t, c, policy.Name).Status.ActiveRemediations; len(got) != 0 {
+		t.Errorf("ActiveRemediations after finishing = %v, want none", got)
+	}
+	if rec, reason, err := r.startRemediation(ctx, policy, second, string(v1alpha1.RemediationReboot), "unhealthy", "", now); err != nil || rec == nil {
+		t.Errorf("startRemediation(worker-2) = %v, %q, %v, want the freed slot", rec, reason, err)
+	}
+}
+
+func TestAcquireDrainSlot_Conflict(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	policy := testPolicy(nil)
+	base := newFakeClient(policy, testNode("worker-1", nil), drainingNode(t, "worker-2", v1alpha1.RemediationInProgress, now))
+
+	// Another replica takes the only slot for worker-2 between our read and our patch.
+	var patches int
+	c := interceptor.NewClient(base, interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			patches++
+			if patches == 1 {
+				latest := getPolicy(t, c, policy.Name)
+				latest.Status.ActiveRemediations = []string{"worker-2"}
+				if err := c.Status().Update(ctx, latest); err != nil {
+					t.Fatalf("Update() error = %v", err)
+				}
+			}
+			return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+		},
+	})
+	r := newWorkflowReconciler(c, nil)
+
+	acquired, reason, err := r.acquireDrainSlot(ctx, policy, testNode("worker-1", nil), now)
+	if err != nil {
+		t.Fatalf("acquireDrainSlot() error = %v", err)
+	}
+	if acquired || reason != "1 of 1 concurrent drains are in progress" {
+		t.Errorf("acquireDrainSlot() = %v, %q, want the slot taken by the other replica", acquired, reason)
+	}
+	if patches != 1 {
+		t.Errorf("status patched %d times, want the conflicting patch only", patches)
+	}
+	if got, want := getPolicy(t, c, policy.Name).Status.ActiveRemediations, []string{"worker-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ActiveRemediations = %v, want %v", got, want)
+	}
+}
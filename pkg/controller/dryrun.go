@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+// maxDryRunRemediations bounds the dry-run entries kept in a policy's status.
+const maxDryRunRemediations = 50
+
+// actionDeescalate is the record action for stepping a node down the escalation ladder.
+const actionDeescalate = "Deescalate"
+
+// actionWait is the dry-run record action for a remediation held back by approval or drain slots.
+const actionWait = "Wait"
+
+// recordDryRun reports the remediation the decision would have performed, as an event on the node,
+// a metric and an entry in the policy status. A remediation an enforcing policy would hold back,
+// see dryRunWait, is reported as a Wait entry naming the reason instead. Actions that do not evict
+// pods are only reported when they differ from the node's previous entry, as an enforcing policy
+// would only apply them once, and so are repeated waits.
+func (r *NodeHealthReconciler) recordDryRun(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, dec decision.Decision, wait string, now time.Time) error {
+	action := remediation.ActionCordonAndDrain
+	switch {
+	case dec.Action == decision.ActionDeescalate:
+		action = actionDeescalate
+	case dec.Step != "":
+		action = string(dec.Step)
+	}
+	evicts := dec.Action == decision.ActionRemediate && decision.Evicts(dec.Step)
+
+	rec := v1alpha1.RemediationRecord{
+		Node:    node.Name,
+		Action:  action,
+		Outcome: v1alpha1.RemediationDryRun,
+		Time:    metav1.NewTime(now),
+		Message: dec.Reason,
+	}
+	message := fmt.Sprintf("Would have %s the node: %s", wouldHave(action, dec), dec.Reason)
+	if evicts && wait != "" {
+		message = fmt.Sprintf("Would not have %s the node yet: %s", wouldHave(action, dec), wait)
+		rec.Action, rec.Message = actionWait, message
+		evicts = false
+	}
+	// Other nodes' entries are appended concurrently, so the check runs against the latest status.
+	var recorded bool
+	err := r.patchPolicyStatus(ctx, policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+		recorded = evicts || !repeatsDryRun(status.DryRunRemediations, node.Name, rec.Action)
+		if !recorded {
+			return false
+		}
+		status.DryRunRemediations = append(status.DryRunRemediations, rec)
+		if n := len(status.DryRunRemediations); n > maxDryRunRemediations {
+			status.DryRunRemediations = status.DryRunRemediations[n-maxDryRunRemediations:]
+		}
+		return true
+	})
+	if err != nil || !recorded {
+		return err
+	}
+
+	if r.Recorder != nil {
+		r.Recorder.Event(node, corev1.EventTypeNormal, "DryRun", message)
+	}
+	dryRunRemediations.WithLabelValues(policy.Name, rec.Action).Inc()
+	return nil
+}
+
+// dryRunWait returns why an enforcing policy would hold back the action on the node, or "".
+// It runs the approval and drain slot checks of the enforced path without their side effects:
+// no approval is requested and no slot is taken, so only drains actually in progress count.
+func (r *NodeHealthReconciler) dryRunWait(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, action string, now time.Time) (string, error) {
+	if requiresApproval(policy) {
+		list, err := r.openApprovals(ctx, policy, node.Name)
+		if err != nil {
+			return "", err
+		}
+		var approval string
+		for i := range list {
+			nr := &list[i]
+			if nr.Spec.Action == action && now.Before(expiresAt(policy, nr)) && (isApproved(nr) || nr.Status.Phase == v1alpha1.NodeRemediationApproved) {
+				approval = nr.Name
+				break
+			}
+		}
+		if approval == "" {
+			return "approval would have been requested with a NodeRemediation", nil
+		}
+	}
+	active, err := r.activeDrains(ctx, policy, now)
+	if err != nil {
+		return "", err
+	}
+	_, reason, err := r.drainSlot(ctx, policy, node, active)
+	return reason, err
+}
+
+// repeatsDryRun reports whether the node's latest dry-run entry is for the same action.
+func repeatsDryRun(entries []v1alpha1.RemediationRecord, nodeName, action string) bool {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Node == nodeName {
+			return entries[i].Action == action
+		}
+	}
+	return false
+}
+
+// wouldHave describes what the action would have done to the node.
+func wouldHave(action string, dec decision.Decision) string {
+	switch action {
+	case string(v1alpha1.RemediationTaint):
+		return "tainted"
+	case string(v1alpha1.RemediationCordon):
+		return "cordoned"
+	case string(v1alpha1.RemediationDrain), remediation.ActionCordonAndDrain:
+		return "cordoned and drained"
+	case string(v1alpha1.RemediationReboot):
+		return "drained and rebooted"
+	case string(v1alpha1.RemediationReplace):
+		return "drained and replaced"
+	case actionDeescalate:
+		if dec.Step == "" {
+			return "taken off the escalation ladder"
+		}
+		return fmt.Sprintf("de-escalated to the %s step", dec.Step)
+	}
+	return action
+}
+
+// lastDryRun returns when the policy would last have evicted pods from a node, so dry runs honor
+// the cooldown like enforced remediations.
+func lastDryRun(policy *v1alpha1.NodeHealingPolicy) time.Time {
+	var last time.Time
+	for _, rec := range policy.Status.DryRunRemediations {
+		if rec.Action == actionDeescalate || rec.Action == actionWait || !decision.Evicts(v1alpha1.RemediationAction(rec.Action)) {
+			continue
+		}
+		if rec.Time.After(last) {
+			last = rec.Time.Time
+		}
+	}
+	return last
+}
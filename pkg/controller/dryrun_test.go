@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+)
+
+func TestRecordDryRun(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) { p.Spec.Mode = v1alpha1.PolicyDryRun })
+	r := &NodeHealthReconciler{Client: newFakeClient(policy)}
+	drain := decision.Decision{Action: decision.ActionRemediate, Reason: "Health score 0.80 exceeds threshold 0.60"}
+	taint := decision.Decision{Action: decision.ActionRemediate, Reason: "Health score 0.45 reaches the Taint step threshold 0.40", Step: v1alpha1.RemediationTaint}
+
+	// Back-to-back reconciles of different nodes start from the same cached policy.
+	for _, name := range []string{"worker-1", "worker-2"} {
+		if err := r.recordDryRun(ctx, policy.DeepCopy(), testNode(name, nil), drain, "", now); err != nil {
+			t.Fatalf("recordDryRun() error = %v", err)
+		}
+	}
+	// A repeated non-evicting action is recorded once, even from a stale copy.
+	for i := 0; i < 2; i++ {
+		if err := r.recordDryRun(ctx, policy.DeepCopy(), testNode("worker-3", nil), taint, "", now); err != nil {
+			t.Fatalf("recordDryRun() error = %v", err)
+		}
+	}
+
+	var got v1alpha1.NodeHealingPolicy
+	if err := r.Get(ctx, client.ObjectKeyFromObject(policy), &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var nodes []string
+	for _, rec := range got.Status.DryRunRemediations {
+		nodes = append(nodes, rec.Node+"/"+rec.Action)
+	}
+	want := []string{"worker-1/CordonAndDrain", "worker-2/CordonAndDrain", "worker-3/Taint"}
+	if len(nodes) != len(want) {
+		t.Fatalf("dry-run entries = %v, want %v", nodes, want)
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Errorf("dry-run entry %d = %s, want %s", i, nodes[i], want[i])
+		}
+	}
+}
+
+func TestDryRunWait(t *testing.T) {
+	now := time.Now()
+	approval := &v1alpha1.NodeRemediation{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1-abc", Labels: map[string]string{v1alpha1.LabelNodeName: "worker-1"}},
+		Spec:       v1alpha1.NodeRemediationSpec{NodeName: "worker-1", PolicyName: "pool-a", Action: "Reboot", Approved: true},
+		Status:     v1alpha1.NodeRemediationStatus{Phase: v1alpha1.NodeRemediationPendingApproval, ExpiresAt: &metav1.Time{Time: now.Add(time.Minute)}},
+	}
+	tests := []struct {
+		name     string
+		approval bool
+		objs     []client.Object
+		draining bool
+		want     string
+	}{
+		{
+			name: "slot free",
+		},
+		{
+			name:     "drain limit reached",
+			draining: true,
+			want:     "1 of 1 concurrent drains are in progress",
+		},
+		{
+			name:     "approval required",
+			approval: true,
+			want:     "approval would have been requested with a NodeRemediation",
+		},
+		{
+			name:     "approved",
+			approval: true,
+			objs:     []client.Object{approval},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+				p.Spec.Mode = v1alpha1.PolicyDryRun
+				if tt.approval {
+					p.Spec.Remediation.Approval = v1alpha1.ApprovalRequired
+				}
+				if tt.draining {
+					p.Status.ActiveRemediations = []string{"worker-2"}
+				}
+			})
+			objs := append([]client.Object{policy, testNode("worker-1", nil), drainingNode(t, "worker-2", v1alpha1.RemediationInProgress, now)}, tt.objs...)
+			c := newFakeClient(objs...)
+			r := newWorkflowReconciler(c, nil)
+
+			got, err := r.dryRunWait(context.TODO(), policy, testNode("worker-1", nil), "Reboot", now)
+			if err != nil {
+				t.Fatalf("dryRunWait() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("dryRunWait() = %q, want %q", got, tt.want)
+			}
+			// Dry runs neither request approval nor take a slot.
+			var requests v1alpha1.NodeRemediationList
+			if err := c.List(context.TODO(), &requests); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(requests.Items) != len(tt.objs) {
+				t.Errorf("NodeRemediations = %d, want %d", len(requests.Items), len(tt.objs))
+			}
+			if active := getPolicy(t, c, policy.Name).Status.ActiveRemediations; len(active) > 1 || (len(active) == 1 && active[0] != "worker-2") {
+				t.Errorf("ActiveRemediations = %v, want no slot taken", active)
+			}
+		})
+	}
+}
+
+func TestRecordDryRun_Wait(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) { p.Spec.Mode = v1alpha1.PolicyDryRun })
+	c := newFakeClient(policy)
+	r := &NodeHealthReconciler{Client: c}
+	reboot := decision.Decision{Action: decision.ActionRemediate, Reason: "Health score 0.80 exceeds threshold 0.60", Step: v1alpha1.RemediationReboot}
+
+	// A remediation held back is recorded once and does not start the cooldown.
+	for i := 0; i < 2; i++ {
+		if err := r.recordDryRun(ctx, policy.DeepCopy(), testNode("worker-1", nil), reboot, "1 of 1 concurrent drains are in progress", now); err != nil {
+			t.Fatalf("recordDryRun() error = %v", err)
+		}
+	}
+	got := getPolicy(t, c, policy.Name)
+	if n := len(got.Status.DryRunRemediations); n != 1 {
+		t.Fatalf("dry-run entries = %d, want 1", n)
+	}
+	rec := got.Status.DryRunRemediations[0]
+	if want := "Would not have drained and rebooted the node yet: 1 of 1 concurrent drains are in progress"; rec.Action != actionWait || rec.Message != want {
+		t.Errorf("dry-run entry = %s %q, want %s %q", rec.Action, rec.Message, actionWait, want)
+	}
+	if last := lastDryRun(got); !last.IsZero() {
+		t.Errorf("lastDryRun() = %v, want zero", last)
+	}
+}
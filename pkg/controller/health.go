@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+)
+
+// healthStatus reads the node's health state from its HealthScoreDegraded condition, so the
+// state survives controller restarts. The condition's transition time is when the state was entered.
+func healthStatus(node *corev1.Node) decision.HealthStatus {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1alpha1.NodeConditionHealthScoreDegraded {
+			return decision.HealthStatus{State: v1alpha1.NodeHealthState(cond.Reason), Since: cond.LastTransitionTime.Time}
+		}
+	}
+	return decision.HealthStatus{}
+}
+
+// syncHealthState publishes the node's health state as the LabelHealthState label and the
+// HealthScoreDegraded condition. Both are only written when the state changes.
+func (r *NodeHealthReconciler) syncHealthState(ctx context.Context, node *corev1.Node, policy *v1alpha1.NodeHealingPolicy, health decision.HealthStatus, score float64, now time.Time) error {
+	state := string(health.State)
+	if node.Labels[v1alpha1.LabelHealthState] != state {
+		base := node.DeepCopy()
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+		node.Labels[v1alpha1.LabelHealthState] = state
+		if err := r.Patch(ctx, node, client.MergeFrom(base)); err != nil {
+			return fmt.Errorf("failed to label node %s: %w", node.Name, err)
+		}
+	}
+
+	if prev := healthStatus(node); prev.State == health.State {
+		return nil
+	}
+	status := corev1.ConditionTrue
+	if health.State == v1alpha1.NodeHealthy {
+		status = corev1.ConditionFalse
+	}
+	cond := corev1.NodeCondition{
+		Type:               v1alpha1.NodeConditionHealthScoreDegraded,
+		Status:             status,
+		LastHeartbeatTime:  metav1.NewTime(now),
+		LastTransitionTime: metav1.NewTime(health.Since),
+		Reason:             state,
+		Message: fmt.Sprintf("Health score %.2f, unhealthy at %.2f, healthy below %.2f",
+			score, policy.Spec.Thresholds.UnhealthyScore, decision.HealthyScore(policy.Spec.Thresholds)),
+	}
+	base := node.DeepCopy()
+	replaced := false
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == cond.Type {
+			node.Status.Conditions[i] = cond
+			replaced = true
+		}
+	}
+	if !replaced {
+		node.Status.Conditions = append(node.Status.Conditions, cond)
+	}
+	// The kubelet owns the other conditions; a strategic merge patch only touches this one.
+	if err := r.Status().Patch(ctx, node, client.StrategicMergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to set health condition on node %s: %w", node.Name, err)
+	}
+	return nil
+}
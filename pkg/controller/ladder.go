@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+// ValidateCloudProvider returns an error if the policy's ladder reboots or replaces nodes but no
+// cloud provider is configured to do it.
+func ValidateCloudProvider(policy *v1alpha1.NodeHealingPolicy, provider cloud.Provider) error {
+	if provider != nil {
+		return nil
+	}
+	for _, step := range policy.Spec.Remediation.Ladder {
+		if step.Action == v1alpha1.RemediationReboot || step.Action == v1alpha1.RemediationReplace {
+			return fmt.Errorf("ladder step %s needs a cloud provider, but none is configured", step.Action)
+		}
+	}
+	return nil
+}
+
+// ladderStep returns the node's step on the policy's escalation ladder, nil if it is on none.
+func (r *NodeHealthReconciler) ladderStep(policy *v1alpha1.NodeHealingPolicy, node *corev1.Node) *decision.StepState {
+	if len(policy.Spec.Remediation.Ladder) == 0 {
+		return nil
+	}
+	step, err := remediation.CurrentLadderStep(node)
+	if err != nil {
+		r.Log.Error(err, "ignoring unreadable ladder step")
+		return nil
+	}
+	if step == nil {
+		return nil
+	}
+	return &decision.StepState{Index: step.Index, Since: step.Since.Time}
+}
+
+// escalate applies a ladder step that does not evict pods to the node. Evicting steps run as
+// a remediation workflow, see advanceRemediation.
+func (r *NodeHealthReconciler) escalate(ctx context.Context, node *corev1.Node, dec decision.Decision, now time.Time) error {
+	var err error
+	switch dec.Step {
+	case v1alpha1.RemediationTaint:
+		err = r.Remediator.TaintNode(ctx, node.Name)
+	case v1alpha1.RemediationCordon:
+		err = r.Remediator.CordonNode(ctx, node.Name)
+	default:
+		err = fmt.Errorf("step %s evicts pods and must run as a remediation workflow", dec.Step)
+	}
+	if err != nil {
+		return err
+	}
+
+	step := &remediation.LadderStep{Index: dec.StepIndex, Action: dec.Step, Since: metav1.NewTime(now)}
+	if err := r.Remediator.SetLadderStep(ctx, node.Name, step); err != nil {
+		return err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(node, corev1.EventTypeWarning, "Escalated", fmt.Sprintf("Escalated to the %s step: %s", dec.Step, dec.Reason))
+	}
+	return nil
+}
+
+// completeLadderStep records the node of a successful remediation on the ladder step of its action.
+// Failures are only logged; the node then stays on its previous step and may be escalated again.
+func (r *NodeHealthReconciler) completeLadderStep(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord) {
+	for i, step := range policy.Spec.Remediation.Ladder {
+		if string(step.Action) != rec.Action {
+			continue
+		}
+		since := metav1.Now()
+		if rec.PhaseTime != nil {
+			since = *rec.PhaseTime
+		}
+		if err := r.Remediator.SetLadderStep(ctx, rec.Node, &remediation.LadderStep{Index: i, Action: step.Action, Since: since}); err != nil {
+			r.Log.Error(err, "failed to record ladder step", "node", rec.Node)
+		}
+		return
+	}
+}
+
+// deescalate undoes the ladder steps above the decision's step. The node keeps the taint or cordon
+// of the steps at or below it.
+func (r *NodeHealthReconciler) deescalate(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, dec decision.Decision, now time.Time) error {
+	var taint, cordon bool
+	for _, step := range policy.Spec.Remediation.Ladder[:dec.StepIndex+1] {
+		if step.Action == v1alpha1.RemediationTaint {
+			taint = true
+		} else {
+			cordon = true
+		}
+	}
+
+	var err error
+	if taint {
+		err = r.Remediator.TaintNode(ctx, node.Name)
+	} else {
+		err = r.Remediator.UntaintNode(ctx, node.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if cordon {
+		err = r.Remediator.CordonNode(ctx, node.Name)
+	} else {
+		err = r.Remediator.UncordonNode(ctx, node.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	var step *remediation.LadderStep
+	if dec.StepIndex >= 0 {
+		step = &remediation.LadderStep{Index: dec.StepIndex, Action: dec.Step, Since: metav1.NewTime(now)}
+	}
+	if err := r.Remediator.SetLadderStep(ctx, node.Name, step); err != nil {
+		return err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(node, corev1.EventTypeNormal, "Deescalated", dec.Reason)
+	}
+	return nil
+}
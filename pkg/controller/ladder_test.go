@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
+)
+
+func TestValidateCloudProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		ladder   []v1alpha1.RemediationAction
+		provider cloud.Provider
+		wantErr  bool
+	}{
+		{name: "no ladder", wantErr: false},
+		{name: "taint and cordon", ladder: []v1alpha1.RemediationAction{v1alpha1.RemediationTaint, v1alpha1.RemediationCordon}, wantErr: false},
+		{name: "reboot without provider", ladder: []v1alpha1.RemediationAction{v1alpha1.RemediationCordon, v1alpha1.RemediationReboot}, wantErr: true},
+		{name: "replace without provider", ladder: []v1alpha1.RemediationAction{v1alpha1.RemediationReplace}, wantErr: true},
+		{name: "replace with provider", ladder: []v1alpha1.RemediationAction{v1alpha1.RemediationReplace}, provider: cloud.NewFakeProvider(), wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+				for _, action := range tt.ladder {
+					p.Spec.Remediation.Ladder = append(p.Spec.Remediation.Ladder, v1alpha1.RemediationStep{Action: action})
+				}
+			})
+			if err := ValidateCloudProvider(policy, tt.provider); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCloudProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
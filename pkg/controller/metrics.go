@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var dryRunRemediations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "nodehealer_dry_run_remediations_total",
+	Help: "Number of remediations DryRun policies would have performed.",
+}, []string{"policy", "action"})
+
+func init() {
+	metrics.Registry.MustRegister(dryRunRemediations)
+}
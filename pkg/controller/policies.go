@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// policySelects reports whether the policy's node selector matches the node's labels.
+// An empty selector matches every node.
+func policySelects(policy *v1alpha1.NodeHealingPolicy, nodeLabels map[string]string) bool {
+	return labels.SelectorFromSet(policy.Spec.NodeSelector).Matches(labels.Set(nodeLabels))
+}
+
+// precedes reports whether policy a takes precedence over policy b: higher priority first,
+// then the older policy, then the lexically smaller name.
+func precedes(a, b *v1alpha1.NodeHealingPolicy) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// matchingPolicies returns the policies selecting the node, ordered by precedence.
+// Policies being deleted are ignored.
+func matchingPolicies(policies []v1alpha1.NodeHealingPolicy, node *corev1.Node) []*v1alpha1.NodeHealingPolicy {
+	var matched []*v1alpha1.NodeHealingPolicy
+	for i := range policies {
+		p := &policies[i]
+		if p.DeletionTimestamp != nil || !policySelects(p, node.Labels) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	sort.Slice(matched, func(i, j int) bool { return precedes(matched[i], matched[j]) })
+	return matched
+}
+
+// policyFor returns the policy covering the node, or nil if no policy selects it.
+func (r *NodeHealthReconciler) policyFor(ctx context.Context, node *corev1.Node) (*v1alpha1.NodeHealingPolicy, error) {
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to list node healing policies: %w", err)
+	}
+	matched := matchingPolicies(policies.Items, node)
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	return matched[0], nil
+}
+
+// nodesSelectedBy returns reconcile keys for every node selected by any of the policies.
+func nodesSelectedBy(ctx context.Context, c client.Reader, policies ...*v1alpha1.NodeHealingPolicy) ([]client.ObjectKey, error) {
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	var keys []client.ObjectKey
+	for i := range nodes.Items {
+		for _, p := range policies {
+			if policySelects(p, nodes.Items[i].Labels) {
+				keys = append(keys, client.ObjectKey{Name: nodes.Items[i].Name})
+				break
+			}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// PolicyReconciler reports overlapping NodeHealingPolicy selectors on the policies' status.
+// Overlaps depend on every policy and every node's labels, so each reconcile recomputes the
+// Conflict condition of all policies.
+type PolicyReconciler struct {
+	client.Client
+	Log logr.Logger
+}
+
+// Reconcile updates the Conflict condition of every policy.
+func (r *PolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list node healing policies: %w", err)
+	}
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	conflicts := make(map[string]*policyConflict, len(policies.Items))
+	for i := range nodes.Items {
+		matched := matchingPolicies(policies.Items, &nodes.Items[i])
+		if len(matched) < 2 {
+			continue
+		}
+		for _, p := range matched {
+			c, ok := conflicts[p.Name]
+			if !ok {
+				c = &policyConflict{with: make(map[string]bool)}
+				conflicts[p.Name] = c
+			}
+			c.nodes++
+			if p != matched[0] {
+				c.overridden++
+			}
+			for _, other := range matched {
+				if other != p {
+					c.with[other.Name] = true
+				}
+			}
+		}
+	}
+
+	for i := range policies.Items {
+		p := &policies.Items[i]
+		if p.DeletionTimestamp != nil {
+			continue
+		}
+		cond := conflicts[p.Name].condition()
+		cond.ObservedGeneration = p.Generation
+		if err := patchPolicyStatus(ctx, r.Client, p, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+			return meta.SetStatusCondition(&status.Conditions, cond)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// policyConflict summarizes the overlaps of one policy.
+type policyConflict struct {
+	nodes      int
+	overridden int
+	with       map[string]bool
+}
+
+func (c *policyConflict) condition() metav1.Condition {
+	if c == nil {
+		return metav1.Condition{
+			Type:    v1alpha1.ConditionConflict,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NoOverlap",
+			Message: "No node is selected by another policy",
+		}
+	}
+	with := make([]string, 0, len(c.with))
+	for name := range c.with {
+		with = append(with, name)
+	}
+	sort.Strings(with)
+	cond := metav1.Condition{
+		Type:    v1alpha1.ConditionConflict,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Overlapping",
+		Message: fmt.Sprintf("%d node(s) are also selected by %s; this policy takes precedence on all of them", c.nodes, strings.Join(with, ", ")),
+	}
+	if c.overridden > 0 {
+		cond.Reason = "Overridden"
+		cond.Message = fmt.Sprintf("%d node(s) are also selected by %s; another policy takes precedence on %d of them", c.nodes, strings.Join(with, ", "), c.overridden)
+	}
+	return cond
+}
+
+// SetupWithManager recomputes conflicts whenever a policy changes or a node is added, removed or relabeled.
+func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.NodeHealingPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Node{},
+			handler.EnqueueRequestsFromMapFunc(r.allPolicies),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
+		Complete(r)
+}
+
+// allPolicies enqueues every policy. The previous labels of a node are not known here, so
+// policies that stopped selecting it must be refreshed as well.
+func (r *PolicyReconciler) allPolicies(ctx context.Context, _ client.Object) []reconcile.Request {
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		r.Log.Error(err, "failed to list node healing policies")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, p := range policies.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: p.Name}})
+	}
+	return requests
+}
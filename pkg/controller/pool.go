@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+)
+
+// poolBudget measures the capacity of the candidate's pool. It returns nil if the policy sets
+// no disruption limits. The pool size comes from the cloud provider when the policy names a pool
+// and a provider is configured, otherwise from the nodes the selector matches; instances the
+// provider reports but that are not registered as nodes count as unavailable.
+func (r *NodeHealthReconciler) poolBudget(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, candidate *corev1.Node) (*decision.PoolBudget, error) {
+	limits := policy.Spec.Limits
+	if limits.MaxUnavailable == nil && limits.MinHealthyNodes == 0 {
+		return nil, nil
+	}
+
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes, client.MatchingLabels(policy.Spec.NodeSelector)); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	draining := make(map[string]bool, len(policy.Status.ActiveRemediations))
+	for _, name := range policy.Status.ActiveRemediations {
+		draining[name] = true
+	}
+	budget := &decision.PoolBudget{Size: len(nodes.Items)}
+	for i := range nodes.Items {
+		// Nodes holding a drain slot may not be cordoned yet but are about to be.
+		if nodeUnavailable(&nodes.Items[i]) || draining[nodes.Items[i].Name] {
+			budget.Unavailable++
+			if nodes.Items[i].Name == candidate.Name {
+				budget.CandidateUnavailable = true
+			}
+		}
+	}
+
+	if r.Cloud != nil && policy.Spec.PoolID != "" {
+		size, err := r.Cloud.GetNodePoolSize(ctx, policy.Spec.PoolID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get size of node pool %s: %w", policy.Spec.PoolID, err)
+		}
+		if missing := size - len(nodes.Items); missing > 0 {
+			budget.Unavailable += missing
+		}
+		budget.Size = size
+	}
+	return budget, nil
+}
+
+// nodeUnavailable reports whether the node is cordoned or not Ready.
+func nodeUnavailable(node *corev1.Node) bool {
+	return node.Spec.Unschedulable || !nodeReady(node)
+}
+
+// nodeReady reports whether the node's Ready condition is True.
+func nodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+)
+
+func TestPoolBudget(t *testing.T) {
+	maxUnavailable := intstr.FromInt(2)
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.Limits.MaxUnavailable = &maxUnavailable
+	})
+	nodes := []*corev1.Node{
+		testNode("worker-1", nil),
+		testNode("worker-2", nil),
+		testNode("worker-3", func(n *corev1.Node) { n.Spec.Unschedulable = true }),
+	}
+
+	tests := []struct {
+		name    string
+		cloud   func() *cloud.FakeProvider
+		want    decision.PoolBudget
+		wantErr bool
+	}{
+		{
+			name: "counted through the selector without a provider",
+			want: decision.PoolBudget{Size: 3, Unavailable: 1},
+		},
+		{
+			name: "unregistered instances count as unavailable",
+			cloud: func() *cloud.FakeProvider {
+				p := cloud.NewFakeProvider()
+				p.PoolSizes["pool-a"] = 5
+				return p
+			},
+			want: decision.PoolBudget{Size: 5, Unavailable: 3},
+		},
+		{
+			name: "provider error",
+			cloud: func() *cloud.FakeProvider {
+				p := cloud.NewFakeProvider()
+				p.Err = errors.New("throttled")
+				return p
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &NodeHealthReconciler{Client: newFakeClient(policy, nodes[0], nodes[1], nodes[2])}
+			if tt.cloud != nil {
+				r.Cloud = tt.cloud()
+			}
+			got, err := r.poolBudget(context.TODO(), policy, nodes[0])
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("poolBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("poolBudget() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,29 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
 	"github.com/example/self-healing-nodepool/pkg/collector"
+	"github.com/example/self-healing-nodepool/pkg/correlation"
 	"github.com/example/self-healing-nodepool/pkg/decision"
 	"github.com/example/self-healing-nodepool/pkg/remediation"
 	"github.com/example/self-healing-nodepool/pkg/scorer"
@@ -20,14 +33,36 @@ import (
 // NodeHealthReconciler reconciles a Node object
 type NodeHealthReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 
 	Collector  collector.NodeSignalCollector
-	Scorer     *scorer.Scorer
+	Staleness  collector.StalenessPolicy
 	Decision   *decision.Engine
+	History    *decision.ScoreHistory
 	Remediator *remediation.Executor
-	Policy     *v1alpha1.NodeHealingPolicy
+
+	// Correlation, if set, looks for attributes shared by the unhealthy nodes of a pool.
+	Correlation *correlation.Analyzer
+
+	// Cloud, if set, provides the live size of node pools for the disruption budget.
+	Cloud cloud.Provider
+
+	// DefaultWeights are used to score nodes whose policy does not set spec.scoring.weights.
+	DefaultWeights map[scorer.MetricName]float64
+
+	// EvaluationInterval is how often a node is re-scored. It must be well below the policy's
+	// evaluation window so the window holds enough samples. Defaults to the evaluation window.
+	EvaluationInterval time.Duration
+
+	// MinCoverage is the share of the scoring weight that must be backed by fresh signals.
+	// Below it the node's health is unknown and no decision is made.
+	MinCoverage float64
+
+	scorers scorerCache
+	// drainMu serializes drain slot acquisition with recording the remediation as in progress.
+	drainMu sync.Mutex
 }
 
 // Reconcile is the main loop.
@@ -38,58 +73,316 @@ func (r *NodeHealthReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// 1. Fetch Node
 	var node corev1.Node
 	if err := r.Get(ctx, req.NamespacedName, &node); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.forgetNode(req.Name)
+			// Keep checking on a replaced node until its replacement is Ready.
+			waiting, err := r.completeReplacement(ctx, req.Name, time.Now())
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if waiting {
+				return ctrl.Result{RequeueAfter: workflowPollInterval}, nil
+			}
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	// 2. Fetch Policy (Simplified: Using a singleton policy passed in struct, or we could fetch CRD)
-	// In production, we'd look up the NodeHealingPolicy matching the node labels.
-	policy := r.Policy
-	// Skip if no policy applies (logic omitted for brevity).
+	// 2. Fetch Policy
+	// The node is covered by the matching policy with the highest precedence; see precedes.
+	policy, err := r.policyFor(ctx, &node)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if policy == nil {
+		log.V(1).Info("no policy covers node, skipping")
+		r.forgetNode(node.Name)
+		return ctrl.Result{}, nil
+	}
+	log = log.WithValues("policy", policy.Name)
+	if policy.Spec.Mode == v1alpha1.PolicyDisabled {
+		log.V(1).Info("policy is disabled, skipping")
+		r.forgetNode(node.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// A remediation in progress runs its workflow to the end before the node is scored again.
+	// Switching the policy to DryRun or Disabled pauses it.
+	if policy.Spec.Mode != v1alpha1.PolicyDryRun {
+		rec, err := remediation.LastRemediation(&node)
+		if err != nil {
+			log.Error(err, "ignoring unreadable remediation record")
+		}
+		if rec != nil && rec.Outcome == v1alpha1.RemediationInProgress {
+			return r.advanceRemediation(ctx, policy, &node, *rec, time.Now())
+		}
+		// A replaced node may come back under its old name without the record; it is not
+		// scored before it is Ready.
+		if replacing(policy, node.Name) != nil {
+			waiting, err := r.completeReplacement(ctx, node.Name, time.Now())
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if waiting {
+				return ctrl.Result{RequeueAfter: workflowPollInterval}, nil
+			}
+		}
+	}
+
+	// Each policy scores with its own weights; an invalid spec is reported on the policy
+	// and its nodes are left alone until it is fixed.
+	nodeScorer, invalid := r.scorers.get(policy, r.DefaultWeights)
+	if invalid == nil {
+		invalid = decision.ValidateWindows(policy.Spec.Remediation.Windows)
+	}
+	if invalid == nil {
+		invalid = ValidateCloudProvider(policy, r.Remediator.Cloud)
+	}
+	if err := r.setConfigCondition(ctx, policy, invalid); err != nil {
+		log.Error(err, "failed to update config condition")
+	}
+	if invalid != nil {
+		log.Error(invalid, "invalid policy spec, skipping node")
+		return ctrl.Result{}, nil
+	}
 
 	// 3. Collect Signals
-	signals, err := r.Collector.CollectSignals(ctx, node.Name)
-	if err != nil {
+	// Flap rates and other aggregates cover the policy's evaluation window.
+	signals, err := r.Collector.CollectSignals(collector.WithEvaluationWindow(ctx, policy.Spec.Thresholds.EvaluationWindow.Duration), node.Name)
+	if collector.IsPartialFailure(err) {
+		// Degraded but trusted: score with whatever the healthy sources reported.
+		log.Info("some signal sources failed, continuing with partial signals", "error", err.Error())
+	} else if err != nil {
 		log.Error(err, "failed to collect signals")
 		return ctrl.Result{}, err // Retry
 	}
 
+	if err := r.syncTelemetryCondition(ctx, policy); err != nil {
+		log.Error(err, "failed to update telemetry condition")
+	}
+
 	// 4. Score
-	score := r.Scorer.CalculateScore(signals)
-	log.Info("node health scored", "score", score)
+	// Missing or stale signals are unknown, not healthy. If too much of the weight is unknown
+	// the score would look reassuringly low, so skip the decision entirely.
+	now := time.Now()
+	requeue := ctrl.Result{RequeueAfter: r.evaluationInterval(policy)}
+	values, unknown := r.Staleness.Evaluate(now, signals, nodeScorer.Metrics())
+	if coverage := nodeScorer.Coverage(values); coverage < r.MinCoverage {
+		log.Info("node health unknown, not enough fresh signals", "coverage", coverage, "unknown", unknown)
+		return requeue, nil
+	}
+	score := nodeScorer.CalculateScore(values)
+	log.Info("node health scored", "score", score, "unknown", unknown)
+	r.History.Record(node.Name, now, score)
+	history := r.History.Samples(node.Name)
+
+	// The health state adds hysteresis, so a score hovering around the threshold does not flap.
+	health := decision.NextHealth(healthStatus(&node), score, history, policy.Spec.Thresholds, now)
+	if err := r.syncHealthState(ctx, &node, policy, health, score, now); err != nil {
+		log.Error(err, "failed to publish health state")
+	}
 
 	// 5. Decide
-	// TODO: Get last remediation time from NodeHealingPolicy Status or Node annotation
-	// CURRENT LIMITATION: The LastEvaluated time is not currently persisted to the NodeHealingPolicy Status.
-	// This means that if the controller restarts, it might lose track of the last remediation time.
-	// Future work: Update NodeHealingPolicy.Status with the last decision time.
-	lastRemediation := time.Time{} // Placeholder
-	dec := r.Decision.Evaluate(score, policy, lastRemediation)
+	// The cooldown applies to the whole pool, so the most recent remediation of any covered node counts.
+	last := lastRemediation(policy)
+	if policy.Spec.Mode == v1alpha1.PolicyDryRun {
+		if dryRun := lastDryRun(policy); dryRun.After(last) {
+			last = dryRun
+		}
+	}
+	// Measuring the pool may call the cloud provider, so only do it for remediation candidates.
+	candidate := health.State == v1alpha1.NodeUnhealthy || decision.Candidate(policy, score)
+	var budget *decision.PoolBudget
+	if candidate {
+		if budget, err = r.poolBudget(ctx, policy, &node); err != nil {
+			log.Error(err, "failed to measure pool capacity")
+			return ctrl.Result{}, err
+		}
+	}
+	// Look for a shared cause while the pool has unhealthy nodes, and once more to clear old findings.
+	var correlated []v1alpha1.CorrelationFinding
+	if r.Correlation != nil && (candidate || len(policy.Status.Correlations) > 0) {
+		if correlated, err = r.analyzeCorrelations(ctx, policy, &node, now); err != nil {
+			log.Error(err, "failed to analyze correlated degradation")
+		}
+	}
+	dec := r.Decision.Decide(decision.Input{
+		Node:            node.Name,
+		Pool:            policy.Name,
+		Score:           score,
+		Policy:          policy,
+		LastRemediation: last,
+		History:         history,
+		Health:          &health,
+		Correlations:    correlated,
+		Step:            r.ladderStep(policy, &node),
+		Budget:          budget,
+		Now:             now,
+	})
+
+	if err := r.syncSystemicCondition(ctx, policy, now); err != nil {
+		log.Error(err, "failed to update systemic failure condition")
+	}
 
 	// 6. Execute
+	action := remediation.ActionCordonAndDrain
+	if dec.Step != "" {
+		action = string(dec.Step)
+	}
+
+	// A dry run stops short of the executor and only reports what would have been done,
+	// including whether approval or the drain limits would have held the remediation back.
+	if policy.Spec.Mode == v1alpha1.PolicyDryRun && (dec.Action == decision.ActionRemediate || dec.Action == decision.ActionDeescalate) {
+		var wait string
+		if dec.Action == decision.ActionRemediate && decision.Evicts(dec.Step) {
+			if wait, err = r.dryRunWait(ctx, policy, &node, action, now); err != nil {
+				log.Error(err, "failed to check approval and drain slots of dry run")
+				return ctrl.Result{}, err
+			}
+		}
+		log.Info("Dry run, not acting on node", "action", dec.Action, "step", dec.Step, "reason", dec.Reason, "wait", wait)
+		if err := r.recordDryRun(ctx, policy, &node, dec, wait, now); err != nil {
+			log.Error(err, "failed to record dry run")
+		}
+		return requeue, nil
+	}
+
+	// Policies requiring approval wait for a user to approve a NodeRemediation before evicting pods.
+	var approval string
+	evicting := dec.Action == decision.ActionRemediate && decision.Evicts(dec.Step)
+	if evicting && requiresApproval(policy) {
+		var wait string
+		approval, wait, err = r.approved(ctx, policy, &node, action, dec.Reason, score, nodeScorer.Breakdown(values), now)
+		if err != nil {
+			log.Error(err, "failed to check approval, not remediating")
+			return ctrl.Result{}, err
+		}
+		if approval == "" {
+			dec = decision.Decision{
+				Action: decision.ActionMonitor,
+				Reason: fmt.Sprintf("Node is unhealthy but %s", wait),
+			}
+		}
+	}
+	// Approvals belong to the episode they were requested in.
+	if requiresApproval(policy) && !candidate && health.State != v1alpha1.NodeDegraded {
+		if err := r.withdrawApprovals(ctx, policy, node.Name); err != nil {
+			log.Error(err, "failed to withdraw approvals of recovered node")
+		}
+	}
+
+	// Only MaxConcurrentDrains nodes of a pool are drained at once, within the disruption
+	// budget; the rest keep waiting.
+	var rec *v1alpha1.RemediationRecord
+	if dec.Action == decision.ActionRemediate && decision.Evicts(dec.Step) {
+		var wait string
+		rec, wait, err = r.startRemediation(ctx, policy, &node, action, dec.Reason, approval, now)
+		if err != nil {
+			log.Error(err, "failed to start remediation, not remediating")
+			return ctrl.Result{}, err
+		}
+		if rec == nil {
+			dec = decision.Decision{
+				Action: decision.ActionMonitor,
+				Reason: fmt.Sprintf("Node is unhealthy but %s", wait),
+			}
+		}
+	}
+
 	switch dec.Action {
 	case decision.ActionRemediate:
-		log.Info("Remediating node", "reason", dec.Reason)
-		if err := r.Remediator.CordonNode(ctx, node.Name); err != nil {
+		log.Info("Remediating node", "step", dec.Step, "reason", dec.Reason)
+		if rec != nil {
+			// Evicting pods takes a while, so it runs as a workflow over several reconciles.
+			if r.Recorder != nil {
+				r.Recorder.Event(&node, corev1.EventTypeWarning, "RemediationStarted", fmt.Sprintf("Started %s: %s", rec.Action, dec.Reason))
+			}
+			return r.advanceRemediation(ctx, policy, &node, *rec, now)
+		}
+		if err := r.escalate(ctx, &node, dec, now); err != nil {
+			log.Error(err, "failed to escalate node", "step", dec.Step)
 			return ctrl.Result{}, err
 		}
-		// Async drain? Or sync? simpler to do sync for now or launch go routine (but dangerous in reconciler)
-		// Better: set state to Draining, return, and let next reconcile loop handle drain progress.
-		// For MVP, simplistic blocking call:
-		if err := r.Remediator.DrainNode(ctx, node.Name); err != nil {
-			log.Error(err, "failed to drain node")
+	case decision.ActionDeescalate:
+		log.Info("De-escalating node", "step", dec.Step, "reason", dec.Reason)
+		if err := r.deescalate(ctx, policy, &node, dec, now); err != nil {
+			log.Error(err, "failed to de-escalate node")
 			return ctrl.Result{}, err
 		}
-		// Trigger Replacement (omitted)
 	case decision.ActionMonitor:
 		log.Info("Monitoring node", "reason", dec.Reason)
 	}
 
 	// Requeue to ensure continuous monitoring even if no events
-	return ctrl.Result{RequeueAfter: policy.Spec.Thresholds.EvaluationWindow.Duration}, nil
+	return requeue, nil
+}
+
+// evaluationInterval is how long to wait before re-scoring a node covered by the policy.
+func (r *NodeHealthReconciler) evaluationInterval(policy *v1alpha1.NodeHealingPolicy) time.Duration {
+	if r.EvaluationInterval > 0 {
+		return r.EvaluationInterval
+	}
+	return policy.Spec.Thresholds.EvaluationWindow.Duration
+}
+
+// forgetNode drops the score history and the collectors' state of a node that is gone or no
+// longer evaluated.
+func (r *NodeHealthReconciler) forgetNode(nodeName string) {
+	r.History.Forget(nodeName)
+	if f, ok := r.Collector.(collector.Forgetter); ok {
+		f.Forget(nodeName)
+	}
 }
 
 func (r *NodeHealthReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Drains list the pods of a node through the cache.
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	}); err != nil {
+		return fmt.Errorf("failed to index pods by node: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Node{}).
+		// Only spec changes move nodes between policies; the controller's own status patches
+		// would otherwise re-evaluate every selected node.
+		Watches(&v1alpha1.NodeHealingPolicy{}, handler.Funcs{
+			CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+				r.enqueueSelectedNodes(ctx, q, e.Object)
+			},
+			// Nodes selected before and after the change may both have moved to another policy.
+			UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+				r.enqueueSelectedNodes(ctx, q, e.ObjectOld, e.ObjectNew)
+			},
+			DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+				r.scorers.forget(e.Object.GetUID())
+				r.enqueueSelectedNodes(ctx, q, e.Object)
+			},
+		}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Approving a NodeRemediation lets its node's remediation proceed right away.
+		Watches(&v1alpha1.NodeRemediation{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			nr, ok := obj.(*v1alpha1.NodeRemediation)
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nr.Spec.NodeName}}}
+		})).
 		Complete(r)
 }
+
+// enqueueSelectedNodes re-evaluates every node selected by one of the given policies.
+func (r *NodeHealthReconciler) enqueueSelectedNodes(ctx context.Context, q workqueue.RateLimitingInterface, objs ...client.Object) {
+	policies := make([]*v1alpha1.NodeHealingPolicy, 0, len(objs))
+	for _, obj := range objs {
+		if p, ok := obj.(*v1alpha1.NodeHealingPolicy); ok {
+			policies = append(policies, p)
+		}
+	}
+	keys, err := nodesSelectedBy(ctx, r.Client, policies...)
+	if err != nil {
+		r.Log.Error(err, "failed to enqueue nodes for policy change")
+		return
+	}
+	for _, key := range keys {
+		q.Add(reconcile.Request{NamespacedName: key})
+	}
+}
@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// replacing returns the policy's record of the node's replacement if one is in progress.
+func replacing(policy *v1alpha1.NodeHealingPolicy, nodeName string) *v1alpha1.RemediationRecord {
+	for i := range policy.Status.Replacements {
+		rec := &policy.Status.Replacements[i].Remediation
+		if policy.Status.Replacements[i].Node == nodeName && rec.Outcome == v1alpha1.RemediationInProgress && rec.Phase == v1alpha1.PhaseReplacing {
+			return rec
+		}
+	}
+	return nil
+}
+
+// sameRemediation reports whether the records are of the same remediation of a node. Record
+// times round-trip through JSON, so they are compared to the second.
+func sameRemediation(a, b v1alpha1.RemediationRecord) bool {
+	return a.Node == b.Node && a.Time.Unix() == b.Time.Unix()
+}
+
+// trackReplacement keeps the status' replacements in step with the remediation record. A node
+// entering the Replacing phase is tracked until its remediation ends. A replacement that claimed
+// a new node keeps the claim for replaceTimeout after it finished, as long as any replacement
+// started before the new node joined may still be waiting.
+func trackReplacement(status *v1alpha1.NodeHealingPolicyStatus, rec v1alpha1.RemediationRecord, now time.Time) {
+	tracked := rec.Outcome == v1alpha1.RemediationInProgress && rec.Phase == v1alpha1.PhaseReplacing
+	kept := make([]v1alpha1.ReplacementRecord, 0, len(status.Replacements)+1)
+	var found bool
+	for _, rep := range status.Replacements {
+		if rep.Node == rec.Node {
+			found = true
+			switch {
+			case sameRemediation(rep.Remediation, rec):
+				rep.Remediation = *rec.DeepCopy()
+			case tracked:
+				// A new node under the same name is being replaced in turn.
+				rep = v1alpha1.ReplacementRecord{Node: rec.Node, Remediation: *rec.DeepCopy()}
+			}
+		}
+		if rep.Remediation.Outcome != v1alpha1.RemediationInProgress &&
+			(rep.Replacement == "" || now.Sub(phaseStart(rep.Remediation)) > replaceTimeout) {
+			continue
+		}
+		kept = append(kept, rep)
+	}
+	if !found && tracked {
+		kept = append(kept, v1alpha1.ReplacementRecord{Node: rec.Node, Remediation: *rec.DeepCopy()})
+	}
+	status.Replacements = kept
+}
+
+// replacementCandidates returns the Ready nodes of the policy that joined since the replacement
+// started, oldest first. Only nodes created after the instance was handed to the cloud provider
+// count, which also covers providers that reuse the node name, and only nodes the policy takes
+// precedence on, so a broad selector does not count new nodes of other pools.
+func (r *NodeHealthReconciler) replacementCandidates(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord) ([]string, error) {
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	var nodes corev1.NodeList
+	if err := r.List(ctx, &nodes, client.MatchingLabels(policy.Spec.NodeSelector)); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	start := phaseStart(rec)
+	var joined []*corev1.Node
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.CreationTimestamp.Time.Before(start) || !nodeReady(node) {
+			continue
+		}
+		if matched := matchingPolicies(policies.Items, node); len(matched) == 0 || matched[0].Name != policy.Name {
+			continue
+		}
+		joined = append(joined, node)
+	}
+	sort.Slice(joined, func(i, j int) bool {
+		if !joined[i].CreationTimestamp.Equal(&joined[j].CreationTimestamp) {
+			return joined[i].CreationTimestamp.Before(&joined[j].CreationTimestamp)
+		}
+		return joined[i].Name < joined[j].Name
+	})
+	names := make([]string, len(joined))
+	for i, node := range joined {
+		names[i] = node.Name
+	}
+	return names, nil
+}
+
+// claimReplacement returns the new node replacing the record's node, or "" if none joined yet.
+// Each new node replaces one node only: the claim is recorded on the policy status with an
+// optimistic lock, and nodes claimed by other replacements, or being replaced themselves, are
+// passed over.
+func (r *NodeHealthReconciler) claimReplacement(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord) (string, error) {
+	candidates, err := r.replacementCandidates(ctx, policy, rec)
+	if err != nil {
+		return "", err
+	}
+	var claimed string
+	err = r.patchPolicyStatus(ctx, policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+		claimed = ""
+		taken := make(map[string]bool, 2*len(status.Replacements))
+		entry := -1
+		for i, rep := range status.Replacements {
+			if rep.Node == rec.Node {
+				entry = i
+				continue
+			}
+			taken[rep.Node] = true
+			if rep.Replacement != "" {
+				taken[rep.Replacement] = true
+			}
+		}
+		if entry >= 0 && status.Replacements[entry].Replacement != "" {
+			claimed = status.Replacements[entry].Replacement
+			return false
+		}
+		for _, name := range candidates {
+			if !taken[name] {
+				claimed = name
+				break
+			}
+		}
+		if claimed == "" {
+			return false
+		}
+		if entry < 0 {
+			status.Replacements = append(status.Replacements, v1alpha1.ReplacementRecord{Node: rec.Node, Remediation: *rec.DeepCopy()})
+			entry = len(status.Replacements) - 1
+		}
+		status.Replacements[entry].Replacement = claimed
+		return true
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to claim replacement of node %s: %w", rec.Node, err)
+	}
+	return claimed, nil
+}
+
+// completeReplacement finishes the replacement of a node that is gone, or came back as a new
+// node object without the remediation record, for every policy still tracking it. The replacement
+// succeeds once a new node in the pool is Ready and fails after replaceTimeout; only then is its
+// drain slot freed. It returns whether a replacement is still waiting for its node.
+func (r *NodeHealthReconciler) completeReplacement(ctx context.Context, nodeName string, now time.Time) (bool, error) {
+	var policies v1alpha1.NodeHealingPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return false, fmt.Errorf("failed to list policies: %w", err)
+	}
+	var waiting bool
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		rec := replacing(policy, nodeName)
+		if rec == nil {
+			continue
+		}
+		replacement, err := r.claimReplacement(ctx, policy, *rec)
+		if err != nil {
+			return false, err
+		}
+		done := *rec.DeepCopy()
+		switch {
+		case replacement != "":
+			done.Phase, done.Outcome = v1alpha1.PhaseReplaced, v1alpha1.RemediationSucceeded
+			done.Progress = fmt.Sprintf("replaced by node %s", replacement)
+		case now.Sub(phaseStart(done)) > phaseTimeout(policy, done.Phase):
+			done.Phase, done.Outcome = v1alpha1.PhaseFailed, v1alpha1.RemediationFailed
+			done.Message = fmt.Sprintf("timed out in phase %s after %s", v1alpha1.PhaseReplacing, phaseTimeout(policy, v1alpha1.PhaseReplacing))
+		default:
+			waiting = true
+			continue
+		}
+		done.PhaseTime = &metav1.Time{Time: now}
+		if err := r.recordPolicyRemediation(ctx, policy, done); err != nil {
+			return false, err
+		}
+		if err := r.releaseDrainSlot(ctx, policy, nodeName); err != nil {
+			return false, err
+		}
+		r.completeApproval(ctx, done)
+		r.forgetNode(nodeName)
+	}
+	return waiting, nil
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
+)
+
+// replacingRecord returns the record of a replacement of the node handed to the provider at start.
+func replacingRecord(nodeName string, start time.Time) v1alpha1.RemediationRecord {
+	return v1alpha1.RemediationRecord{
+		Node:      nodeName,
+		Action:    string(v1alpha1.RemediationReplace),
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(start.Add(-time.Minute)),
+		Message:   "Health score 0.80 exceeds threshold 0.60",
+		Phase:     v1alpha1.PhaseReplacing,
+		PhaseTime: &metav1.Time{Time: start},
+	}
+}
+
+// joinedNode returns a Ready node of pool-a created at the time.
+func joinedNode(name string, created time.Time) *corev1.Node {
+	return testNode(name, func(n *corev1.Node) {
+		n.CreationTimestamp = metav1.NewTime(created)
+	})
+}
+
+func getPolicy(t *testing.T, c client.Client, name string) *v1alpha1.NodeHealingPolicy {
+	t.Helper()
+	var policy v1alpha1.NodeHealingPolicy
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: name}, &policy); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return &policy
+}
+
+func TestReplacement_Workflow(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	rec := replacingRecord("worker-1", now)
+	rec.Phase = v1alpha1.PhaseDrained
+	node, policy := remediatingNode(t, rec, func(n *corev1.Node) {
+		n.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	})
+	policy.Status.LastRemediation = nil
+	c := newFakeClient(node, policy)
+	provider := cloud.NewFakeProvider()
+	r := newWorkflowReconciler(c, provider)
+
+	if _, got, err := advance(t, r, now); err != nil || got.Phase != v1alpha1.PhaseReplacing {
+		t.Fatalf("advanceRemediation() = %v, phase %s, want Replacing", err, got.Phase)
+	}
+	if len(provider.Replaced) != 1 || provider.Replaced[0] != "i-worker-1" {
+		t.Errorf("replaced %v, want i-worker-1", provider.Replaced)
+	}
+	if replacing(getPolicy(t, c, "pool-a"), "worker-1") == nil {
+		t.Fatalf("replacement of worker-1 is not tracked on the policy")
+	}
+
+	// The provider removes the old node before its replacement is Ready; it keeps its slot.
+	if err := c.Delete(ctx, node); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	waiting, err := r.completeReplacement(ctx, "worker-1", now.Add(time.Minute))
+	if err != nil || !waiting {
+		t.Fatalf("completeReplacement() = %v, %v, want waiting", waiting, err)
+	}
+	active, err := r.activeDrains(ctx, getPolicy(t, c, "pool-a"), now.Add(time.Minute))
+	if err != nil || len(active) != 1 || active[0] != "worker-1" {
+		t.Errorf("activeDrains() = %v, %v, want worker-1", active, err)
+	}
+
+	if err := c.Create(ctx, joinedNode("worker-9", now.Add(2*time.Minute))); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	waiting, err = r.completeReplacement(ctx, "worker-1", now.Add(3*time.Minute))
+	if err != nil || waiting {
+		t.Fatalf("completeReplacement() = %v, %v, want done", waiting, err)
+	}
+	got := getPolicy(t, c, "pool-a")
+	if last := got.Status.LastRemediation; last == nil || last.Outcome != v1alpha1.RemediationSucceeded || last.Progress != "replaced by node worker-9" || last.Message != rec.Message {
+		t.Errorf("last remediation = %+v, want replaced by worker-9", last)
+	}
+	if len(got.Status.ActiveRemediations) != 0 {
+		t.Errorf("drain slots held by %v after the replacement", got.Status.ActiveRemediations)
+	}
+	if len(got.Status.Replacements) != 1 || got.Status.Replacements[0].Replacement != "worker-9" {
+		t.Errorf("replacements = %+v, want worker-1 claiming worker-9", got.Status.Replacements)
+	}
+}
+
+func TestReplacement_ClaimsOneNodeEach(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(-10 * time.Minute)
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.Limits.MaxConcurrentDrains = 2
+		p.Status.ActiveRemediations = []string{"worker-1", "worker-2"}
+		p.Status.Replacements = []v1alpha1.ReplacementRecord{
+			{Node: "worker-1", Remediation: replacingRecord("worker-1", start)},
+			{Node: "worker-2", Remediation: replacingRecord("worker-2", start)},
+		}
+	})
+	c := newFakeClient(policy, joinedNode("worker-3", start.Add(time.Minute)))
+	r := newWorkflowReconciler(c, cloud.NewFakeProvider())
+
+	if waiting, err := r.completeReplacement(ctx, "worker-1", now); err != nil || waiting {
+		t.Fatalf("completeReplacement(worker-1) = %v, %v, want done", waiting, err)
+	}
+	// worker-3 already replaced worker-1, so worker-2 waits for a node of its own.
+	if waiting, err := r.completeReplacement(ctx, "worker-2", now); err != nil || !waiting {
+		t.Fatalf("completeReplacement(worker-2) = %v, %v, want waiting", waiting, err)
+	}
+	if got := getPolicy(t, c, "pool-a").Status.ActiveRemediations; len(got) != 1 || got[0] != "worker-2" {
+		t.Errorf("drain slots = %v, want worker-2", got)
+	}
+
+	if err := c.Create(ctx, joinedNode("worker-4", now)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if waiting, err := r.completeReplacement(ctx, "worker-2", now); err != nil || waiting {
+		t.Fatalf("completeReplacement(worker-2) = %v, %v, want done", waiting, err)
+	}
+	claims := map[string]string{}
+	for _, rep := range getPolicy(t, c, "pool-a").Status.Replacements {
+		claims[rep.Node] = rep.Replacement
+	}
+	if claims["worker-1"] != "worker-3" || claims["worker-2"] != "worker-4" {
+		t.Errorf("claims = %v, want worker-1 by worker-3 and worker-2 by worker-4", claims)
+	}
+}
+
+func TestReplacement_OtherPoolsDoNotCount(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(-10 * time.Minute)
+	// pool-a selects every node, but pool-b takes precedence on its own nodes.
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Spec.NodeSelector = nil
+		p.Status.ActiveRemediations = []string{"worker-1"}
+		p.Status.Replacements = []v1alpha1.ReplacementRecord{
+			{Node: "worker-1", Remediation: replacingRecord("worker-1", start)},
+		}
+	})
+	other := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Name, p.UID = "pool-b", "pool-b"
+		p.Spec.NodeSelector = map[string]string{"pool": "b"}
+		p.Spec.Priority = 10
+	})
+	autoscaled := joinedNode("worker-b", start.Add(time.Minute))
+	autoscaled.Labels["pool"] = "b"
+	c := newFakeClient(policy, other, autoscaled)
+	r := newWorkflowReconciler(c, cloud.NewFakeProvider())
+
+	if waiting, err := r.completeReplacement(ctx, "worker-1", now); err != nil || !waiting {
+		t.Fatalf("completeReplacement() = %v, %v, want waiting", waiting, err)
+	}
+}
+
+func TestReplacement_Timeout(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now().Truncate(time.Second)
+	start := now.Add(-replaceTimeout - time.Minute)
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Status.ActiveRemediations = []string{"worker-1"}
+		p.Status.Replacements = []v1alpha1.ReplacementRecord{
+			{Node: "worker-1", Remediation: replacingRecord("worker-1", start)},
+		}
+	})
+	// A node that joined before the instance was handed to the provider is no replacement.
+	c := newFakeClient(policy, joinedNode("worker-2", start.Add(-time.Minute)))
+	r := newWorkflowReconciler(c, cloud.NewFakeProvider())
+
+	if waiting, err := r.completeReplacement(ctx, "worker-1", now); err != nil || waiting {
+		t.Fatalf("completeReplacement() = %v, %v, want done", waiting, err)
+	}
+	got := getPolicy(t, c, "pool-a")
+	if last := got.Status.LastRemediation; last == nil || last.Outcome != v1alpha1.RemediationFailed || last.Message != "timed out in phase Replacing after 30m0s" {
+		t.Errorf("last remediation = %+v, want timed out", last)
+	}
+	if len(got.Status.ActiveRemediations) != 0 || len(got.Status.Replacements) != 0 {
+		t.Errorf("status = %+v, want no drain slots or replacements left", got.Status)
+	}
+}
+
+func TestTrackReplacement(t *testing.T) {
+	now := time.Now()
+	finished := func(node, replacement string, at time.Time) v1alpha1.ReplacementRecord {
+		rec := replacingRecord(node, at)
+		rec.Phase, rec.Outcome = v1alpha1.PhaseReplaced, v1alpha1.RemediationSucceeded
+		return v1alpha1.ReplacementRecord{Node: node, Remediation: rec, Replacement: replacement}
+	}
+	status := v1alpha1.NodeHealingPolicyStatus{Replacements: []v1alpha1.ReplacementRecord{
+		finished("worker-1", "worker-5", now.Add(-replaceTimeout-time.Minute)),
+		finished("worker-2", "worker-6", now.Add(-time.Minute)),
+		{Node: "worker-3", Remediation: replacingRecord("worker-3", now)},
+	}}
+
+	trackReplacement(&status, replacingRecord("worker-4", now), now)
+	failed := replacingRecord("worker-3", now)
+	failed.Phase, failed.Outcome = v1alpha1.PhaseFailed, v1alpha1.RemediationFailed
+	trackReplacement(&status, failed, now)
+
+	var nodes []string
+	for _, rep := range status.Replacements {
+		nodes = append(nodes, rep.Node)
+	}
+	// worker-1's claim expired and worker-3 failed without one.
+	if len(nodes) != 2 || nodes[0] != "worker-2" || nodes[1] != "worker-4" {
+		t.Errorf("tracked %v, want worker-2 and worker-4", nodes)
+	}
+}
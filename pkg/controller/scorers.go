@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/scorer"
+)
+
+// scorerCache holds one Scorer per policy, rebuilt when the policy's generation changes.
+type scorerCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]scorerEntry
+}
+
+type scorerEntry struct {
+	generation int64
+	scorer     *scorer.Scorer
+	err        error
+}
+
+// get returns the scorer for the policy, building it on first use or after the spec changed.
+// Invalid specs are cached too, so a broken policy is not re-validated on every reconcile.
+func (c *scorerCache) get(policy *v1alpha1.NodeHealingPolicy, defaultWeights map[scorer.MetricName]float64) (*scorer.Scorer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[policy.UID]; ok && e.generation == policy.Generation {
+		return e.scorer, e.err
+	}
+	if c.entries == nil {
+		c.entries = make(map[types.UID]scorerEntry)
+	}
+	s, err := scorer.NewPolicyScorer(policy.Spec.Scoring, defaultWeights)
+	c.entries[policy.UID] = scorerEntry{generation: policy.Generation, scorer: s, err: err}
+	return s, err
+}
+
+// forget drops the cached scorer of a deleted policy.
+func (c *scorerCache) forget(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, uid)
+}
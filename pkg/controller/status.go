@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// setPolicyCondition records a condition on the policy's status if it changed.
+func (r *NodeHealthReconciler) setPolicyCondition(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, cond metav1.Condition) error {
+	cond.ObservedGeneration = policy.Generation
+	return r.patchPolicyStatus(ctx, policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+		return meta.SetStatusCondition(&status.Conditions, cond)
+	})
+}
+
+// patchPolicyStatus applies mutate to the policy's status and patches it if mutate reports a change.
+func (r *NodeHealthReconciler) patchPolicyStatus(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, mutate func(*v1alpha1.NodeHealingPolicyStatus) bool) error {
+	return patchPolicyStatus(ctx, r.Client, policy, mutate)
+}
+
+// patchPolicyStatus applies mutate to the latest status of the policy and patches it if mutate
+// reports a change. Status lists are replaced whole by a merge patch, so the patch is made with
+// an optimistic lock and mutate is applied again on conflict; policy is updated to the result.
+func patchPolicyStatus(ctx context.Context, c client.Client, policy *v1alpha1.NodeHealingPolicy, mutate func(*v1alpha1.NodeHealingPolicyStatus) bool) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var latest v1alpha1.NodeHealingPolicy
+		if err := c.Get(ctx, client.ObjectKeyFromObject(policy), &latest); err != nil {
+			return err
+		}
+		base := latest.DeepCopy()
+		if !mutate(&latest.Status) {
+			policy.Status = latest.Status
+			return nil
+		}
+		if err := c.Status().Patch(ctx, &latest, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		policy.Status = latest.Status
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch status of policy %s: %w", policy.Name, err)
+	}
+	return nil
+}
+
+// syncTelemetryCondition mirrors the decision engine's telemetry gate into the policy status.
+func (r *NodeHealthReconciler) syncTelemetryCondition(ctx context.Context, policy *v1alpha1.NodeHealingPolicy) error {
+	cond := metav1.Condition{
+		Type:    v1alpha1.ConditionTelemetryHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CircuitClosed",
+		Message: "Telemetry is healthy",
+	}
+	if healthy, reason := r.Decision.TelemetryHealthy(); !healthy {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "CircuitOpen"
+		cond.Message = reason
+	}
+	return r.setPolicyCondition(ctx, policy, cond)
+}
+
+// setConfigCondition records whether the policy spec is valid.
+func (r *NodeHealthReconciler) setConfigCondition(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, invalid error) error {
+	cond := metav1.Condition{
+		Type:    v1alpha1.ConditionConfigValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "Policy spec is valid",
+	}
+	if invalid != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "InvalidSpec"
+		cond.Message = invalid.Error()
+	}
+	return r.setPolicyCondition(ctx, policy, cond)
+}
+
+// recordPolicyRemediation stores the pool's most recent remediation on the policy status and
+// keeps its replacements in step with it. The outcome of an earlier remediation does not replace
+// a later one, which the pool cooldown counts from.
+func (r *NodeHealthReconciler) recordPolicyRemediation(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec v1alpha1.RemediationRecord) error {
+	return r.patchPolicyStatus(ctx, policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+		if last := status.LastRemediation; last == nil || sameRemediation(*last, rec) || !rec.Time.Before(&last.Time) {
+			status.LastRemediation = rec.DeepCopy()
+		}
+		trackReplacement(status, rec, time.Now())
+		return true
+	})
+}
+
+// syncSystemicCondition mirrors the systemic failure breaker into the policy status and emits
+// an event when remediation of the pool is halted.
+func (r *NodeHealthReconciler) syncSystemicCondition(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, now time.Time) error {
+	cond := metav1.Condition{
+		Type:    v1alpha1.ConditionSystemicFailure,
+		Status:  metav1.ConditionFalse,
+		Reason:  "NodeLocal",
+		Message: "Unhealthy nodes are within the systemic failure limit",
+	}
+	tripped, reason := r.Decision.Systemic.Tripped(policy.Name, now)
+	if tripped {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "TooManyUnhealthyNodes"
+		cond.Message = "Remediation is halted: " + reason
+	}
+	if tripped && !meta.IsStatusConditionTrue(policy.Status.Conditions, v1alpha1.ConditionSystemicFailure) && r.Recorder != nil {
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "SystemicFailure", cond.Message)
+	}
+	return r.setPolicyCondition(ctx, policy, cond)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+func TestPatchPolicyStatus_ConcurrentWriters(t *testing.T) {
+	ctx := context.TODO()
+	c := newFakeClient(&v1alpha1.NodeHealingPolicy{ObjectMeta: metav1.ObjectMeta{Name: "pool-a"}})
+
+	// Both writers start from the same cached copy of the policy.
+	var cached v1alpha1.NodeHealingPolicy
+	if err := c.Get(ctx, client.ObjectKey{Name: "pool-a"}, &cached); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	first, second := cached.DeepCopy(), cached.DeepCopy()
+	for _, w := range []struct {
+		policy *v1alpha1.NodeHealingPolicy
+		cond   string
+	}{{first, v1alpha1.ConditionConflict}, {second, v1alpha1.ConditionTelemetryHealthy}} {
+		cond := metav1.Condition{Type: w.cond, Status: metav1.ConditionTrue, Reason: "Test"}
+		if err := patchPolicyStatus(ctx, c, w.policy, func(status *v1alpha1.NodeHealingPolicyStatus) bool {
+			return meta.SetStatusCondition(&status.Conditions, cond)
+		}); err != nil {
+			t.Fatalf("patchPolicyStatus() error = %v", err)
+		}
+	}
+
+	var got v1alpha1.NodeHealingPolicy
+	if err := c.Get(ctx, client.ObjectKey{Name: "pool-a"}, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for _, cond := range []string{v1alpha1.ConditionConflict, v1alpha1.ConditionTelemetryHealthy} {
+		if meta.FindStatusCondition(got.Status.Conditions, cond) == nil {
+			t.Errorf("condition %s was lost, conditions = %v", cond, got.Status.Conditions)
+		}
+	}
+}
+
+func TestRecordPolicyRemediation_KeepsLatest(t *testing.T) {
+	ctx := context.TODO()
+	policy := testPolicy(nil)
+	c := newFakeClient(policy)
+	r := newWorkflowReconciler(c, nil)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	first := v1alpha1.RemediationRecord{Node: "worker-1", Action: "Reboot", Outcome: v1alpha1.RemediationInProgress, Time: metav1.NewTime(start)}
+	second := v1alpha1.RemediationRecord{Node: "worker-2", Action: "Reboot", Outcome: v1alpha1.RemediationInProgress, Time: metav1.NewTime(start.Add(time.Minute))}
+	done := *first.DeepCopy()
+	done.Outcome = v1alpha1.RemediationSucceeded
+	for _, rec := range []v1alpha1.RemediationRecord{first, second, done} {
+		if err := r.recordPolicyRemediation(ctx, policy, rec); err != nil {
+			t.Fatalf("recordPolicyRemediation() error = %v", err)
+		}
+	}
+
+	// The first remediation finishing does not move the pool cooldown back.
+	got := getPolicy(t, c, policy.Name)
+	if last := lastRemediation(got); !last.Equal(second.Time.Time) {
+		t.Errorf("lastRemediation() = %v, want %v", last, second.Time.Time)
+	}
+	if got.Status.LastRemediation.Node != second.Node {
+		t.Errorf("LastRemediation.Node = %q, want %q", got.Status.LastRemediation.Node, second.Node)
+	}
+
+	// Annotations of nodes covered by the policy are not read.
+	if err := c.Create(ctx, testNode("worker-3", func(n *corev1.Node) {
+		n.Annotations = map[string]string{remediation.AnnotationLastRemediation: `{"node":"worker-3","time":"` + time.Now().Format(time.RFC3339) + `"}`}
+	})); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if last := lastRemediation(getPolicy(t, c, policy.Name)); !last.Equal(second.Time.Time) {
+		t.Errorf("lastRemediation() = %v, want %v", last, second.Time.Time)
+	}
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+const (
+	// workflowPollInterval is how often a remediation waiting on the cluster or the cloud provider
+	// checks on its progress.
+	workflowPollInterval = 10 * time.Second
+
+	// rebootTimeout bounds how long a node may take to come back from a reboot.
+	rebootTimeout = 15 * time.Minute
+
+	// replaceTimeout bounds how long the cloud provider may take to bring up a Ready replacement.
+	replaceTimeout = 30 * time.Minute
+)
+
+// phaseTimeout is how long a remediation may stay in the phase before it fails.
+func phaseTimeout(policy *v1alpha1.NodeHealingPolicy, phase v1alpha1.RemediationPhase) time.Duration {
+	switch phase {
+	case v1alpha1.PhaseRebooting:
+		return rebootTimeout
+	case v1alpha1.PhaseReplacing:
+		return replaceTimeout
+	}
+	if timeout := policy.Spec.Remediation.DrainTimeout.Duration; timeout > 0 {
+		return timeout
+	}
+	return remediation.DefaultDrainTimeout
+}
+
+// phaseStart returns when the remediation entered its current phase.
+func phaseStart(rec v1alpha1.RemediationRecord) time.Time {
+	if rec.PhaseTime != nil {
+		return rec.PhaseTime.Time
+	}
+	return rec.Time.Time
+}
+
+// advanceRemediation moves the node's in-progress remediation one step forward:
+//
+//	Pending → Cordoned → Draining → Drained [→ Rebooting → Rebooted | → Replacing → Replaced]
+//
+// Every step is persisted before the next reconcile picks up from it, so the workflow resumes
+// after a controller restart, and repeating a step is harmless. Waiting steps requeue instead of
+// blocking the worker. A step that does not finish within its timeout fails the remediation.
+func (r *NodeHealthReconciler) advanceRemediation(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, node *corev1.Node, rec v1alpha1.RemediationRecord, now time.Time) (ctrl.Result, error) {
+	log := r.Log.WithValues("node", node.Name, "policy", policy.Name, "action", rec.Action, "phase", rec.Phase)
+	next := ctrl.Result{Requeue: true}
+	poll := ctrl.Result{RequeueAfter: workflowPollInterval}
+
+	if rec.Phase != v1alpha1.PhasePending && now.Sub(phaseStart(rec)) > phaseTimeout(policy, rec.Phase) {
+		err := fmt.Errorf("timed out in phase %s after %s", rec.Phase, phaseTimeout(policy, rec.Phase))
+		if rec.Phase == v1alpha1.PhaseDraining {
+			err = fmt.Errorf("%w: %s", err, rec.Progress)
+		}
+		r.finishRemediation(ctx, policy, rec, err)
+		return ctrl.Result{}, nil
+	}
+
+	switch rec.Phase {
+	case v1alpha1.PhasePending, "":
+		if err := r.Remediator.CordonNode(ctx, node.Name); err != nil {
+			return ctrl.Result{}, err
+		}
+		return next, r.setRemediationPhase(ctx, policy, &rec, v1alpha1.PhaseCordoned, now)
+
+	case v1alpha1.PhaseCordoned:
+		return next, r.setRemediationPhase(ctx, policy, &rec, v1alpha1.PhaseDraining, now)
+
+	case v1alpha1.PhaseDraining:
+		// Each pass evicts the pods once and requeues, polling while evicted pods terminate and
+		// backing off while evictions are refused. The retry time is kept in the record, so a
+		// restart does not retry early; passes continue until the drain timeout fails the remediation.
+		if retry := rec.EvictionRetryTime; retry != nil && now.Before(retry.Time) {
+			return ctrl.Result{RequeueAfter: retry.Sub(now)}, nil
+		}
+		result, err := r.Remediator.DrainNode(ctx, node.Name)
+		if result == nil {
+			return ctrl.Result{}, err
+		}
+		if err != nil {
+			log.Info("some evictions failed, retrying", "error", err.Error())
+		}
+		prev, refused := rec, rec.EvictionRetries+1
+		rec.Progress, rec.EvictionRetries, rec.EvictionRetryTime = result.String(), 0, nil
+		if result.Complete() {
+			return next, r.setRemediationPhase(ctx, policy, &rec, v1alpha1.PhaseDrained, now)
+		}
+		wait := workflowPollInterval
+		if len(result.Blocked) > 0 {
+			wait = remediation.EvictionRetryDelay(refused)
+			rec.EvictionRetries, rec.EvictionRetryTime = refused, &metav1.Time{Time: now.Add(wait)}
+		}
+		// Keep the blocked pods, their budgets and the retry visible in the record.
+		if rec.Progress != prev.Progress || rec.EvictionRetries != prev.EvictionRetries {
+			log.Info("drain incomplete", "result", rec.Progress, "retryAfter", wait)
+			if err := r.recordRemediation(ctx, policy, rec); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: wait}, nil
+
+	case v1alpha1.PhaseDrained:
+		// The next phase is persisted before the cloud provider is called, so a call made just
+		// before a restart is not made again. A call that fails goes back to Drained and is retried
+		// with backoff until the phase times out. Instances that cannot be addressed fail right away.
+		switch rec.Action {
+		case string(v1alpha1.RemediationReboot):
+			// The boot ID is kept from before the first call, and a node that came back with a new
+			// one since is not rebooted again.
+			if rec.BootID == "" {
+				rec.BootID = node.Status.NodeInfo.BootID
+			}
+			drained := rec
+			if err := r.setRemediationPhase(ctx, policy, &rec, v1alpha1.PhaseRebooting, now); err != nil {
+				return ctrl.Result{}, err
+			}
+			if node.Status.NodeInfo.BootID == rec.BootID {
+				if err := r.Remediator.RebootNode(ctx, node); err != nil {
+					return r.providerFailed(ctx, policy, drained, err)
+				}
+			}
+			return poll, nil
+		case string(v1alpha1.RemediationReplace):
+			// Nodes joining from the start of the Replacing phase on count as the replacement.
+			drained := rec
+			if err := r.setRemediationPhase(ctx, policy, &rec, v1alpha1.PhaseReplacing, now); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Remediator.ReplaceNode(ctx, node); err != nil {
+				return r.providerFailed(ctx, policy, drained, err)
+			}
+			return poll, nil
+		}
+		r.finishRemediation(ctx, policy, rec, nil)
+		return ctrl.Result{}, nil
+
+	case v1alpha1.PhaseRebooting:
+		if node.Status.NodeInfo.BootID == rec.BootID || !nodeReady(node) {
+			return poll, nil
+		}
+		rec.Phase, rec.PhaseTime = v1alpha1.PhaseRebooted, &metav1.Time{Time: now}
+		r.finishRemediation(ctx, policy, rec, nil)
+		return ctrl.Result{}, nil
+
+	case v1alpha1.PhaseReplacing:
+		// The provider may remove the old node only after its replacement joined the pool.
+		// Otherwise completeReplacement finishes the remediation once the node is gone.
+		replacement, err := r.claimReplacement(ctx, policy, rec)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if replacement == "" {
+			return poll, nil
+		}
+		rec.Phase, rec.PhaseTime = v1alpha1.PhaseReplaced, &metav1.Time{Time: now}
+		rec.Progress = fmt.Sprintf("replaced by node %s", replacement)
+		r.finishRemediation(ctx, policy, rec, nil)
+		return ctrl.Result{}, nil
+	}
+
+	r.finishRemediation(ctx, policy, rec, fmt.Errorf("unknown remediation phase %q", rec.Phase))
+	return ctrl.Result{}, nil
+}
+
+// providerFailed handles a failed cloud provider call made for the drained remediation. It fails
+// the remediation if the provider cannot be asked to act on the node. Otherwise the remediation
+// goes back to Drained, so the call is made again, and the error is returned so the node is
+// requeued with backoff.
+func (r *NodeHealthReconciler) providerFailed(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, drained v1alpha1.RemediationRecord, err error) (ctrl.Result, error) {
+	if errors.Is(err, remediation.ErrNoInstance) {
+		r.finishRemediation(ctx, policy, drained, err)
+		return ctrl.Result{}, nil
+	}
+	if recErr := r.recordRemediation(ctx, policy, drained); recErr != nil {
+		r.Log.Error(recErr, "failed to record remediation for retry", "node", drained.Node)
+	}
+	return ctrl.Result{}, err
+}
+
+// setRemediationPhase persists the remediation's next phase.
+func (r *NodeHealthReconciler) setRemediationPhase(ctx context.Context, policy *v1alpha1.NodeHealingPolicy, rec *v1alpha1.RemediationRecord, phase v1alpha1.RemediationPhase, now time.Time) error {
+	rec.Phase = phase
+	rec.PhaseTime = &metav1.Time{Time: now}
+	return r.recordRemediation(ctx, policy, *rec)
+}
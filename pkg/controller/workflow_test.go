@@ -0,0 +1,472 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+	"github.com/example/self-healing-nodepool/pkg/cloud"
+	"github.com/example/self-healing-nodepool/pkg/decision"
+	"github.com/example/self-healing-nodepool/pkg/remediation"
+)
+
+// newWorkflowReconciler returns a reconciler as it is after a controller (re)start.
+func newWorkflowReconciler(c client.Client, provider cloud.Provider) *NodeHealthReconciler {
+	return &NodeHealthReconciler{
+		Client:  c,
+		Scheme:  c.Scheme(),
+		History: decision.NewScoreHistory(10),
+		Remediator: &remediation.Executor{
+			Client:     c,
+			KubeClient: kubefake.NewSimpleClientset(),
+			Cloud:      provider,
+		},
+	}
+}
+
+// remediatingNode returns worker-1 with the remediation record and its policy holding the drain slot.
+func remediatingNode(t *testing.T, rec v1alpha1.RemediationRecord, mutate func(*corev1.Node)) (*corev1.Node, *v1alpha1.NodeHealingPolicy) {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	node := testNode("worker-1", func(n *corev1.Node) {
+		n.Annotations = map[string]string{remediation.AnnotationLastRemediation: string(value)}
+		n.Status.NodeInfo.BootID = "boot-1"
+		if mutate != nil {
+			mutate(n)
+		}
+	})
+	policy := testPolicy(func(p *v1alpha1.NodeHealingPolicy) {
+		p.Status.ActiveRemediations = []string{node.Name}
+		p.Status.LastRemediation = rec.DeepCopy()
+	})
+	return node, policy
+}
+
+// advance runs one reconcile of the node's persisted remediation and returns the record it left.
+func advance(t *testing.T, r *NodeHealthReconciler, now time.Time) (ctrl.Result, *v1alpha1.RemediationRecord, error) {
+	t.Helper()
+	ctx := context.TODO()
+	var node corev1.Node
+	if err := r.Get(ctx, client.ObjectKey{Name: "worker-1"}, &node); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var policy v1alpha1.NodeHealingPolicy
+	if err := r.Get(ctx, client.ObjectKey{Name: "pool-a"}, &policy); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	rec, err := remediation.LastRemediation(&node)
+	if err != nil || rec == nil {
+		t.Fatalf("LastRemediation() = %v, %v", rec, err)
+	}
+	result, advanceErr := r.advanceRemediation(ctx, &policy, &node, *rec, now)
+	if err := r.Get(ctx, client.ObjectKey{Name: "worker-1"}, &node); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if rec, err = remediation.LastRemediation(&node); err != nil {
+		t.Fatalf("LastRemediation() error = %v", err)
+	}
+	return result, rec, advanceErr
+}
+
+// activeRemediations returns the nodes holding a drain slot of pool-a.
+func activeRemediations(t *testing.T, c client.Client) []string {
+	t.Helper()
+	var policy v1alpha1.NodeHealingPolicy
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "pool-a"}, &policy); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	return policy.Status.ActiveRemediations
+}
+
+func TestAdvanceRemediation_Resume(t *testing.T) {
+	now := time.Now()
+	node, policy := remediatingNode(t, v1alpha1.RemediationRecord{
+		Node:      "worker-1",
+		Action:    remediation.ActionCordonAndDrain,
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(now),
+		Message:   "Health score 0.80 exceeds threshold 0.60",
+		Phase:     v1alpha1.PhasePending,
+		PhaseTime: &metav1.Time{Time: now},
+	}, nil)
+	c := newFakeClient(node, policy)
+
+	// Every pass runs on a fresh reconciler, as after a restart, and picks up from the persisted phase.
+	for _, want := range []v1alpha1.RemediationPhase{
+		v1alpha1.PhaseCordoned,
+		v1alpha1.PhaseDraining,
+		v1alpha1.PhaseDrained,
+	} {
+		_, rec, err := advance(t, newWorkflowReconciler(c, nil), now)
+		if err != nil {
+			t.Fatalf("advanceRemediation() error = %v", err)
+		}
+		if rec.Phase != want || rec.Outcome != v1alpha1.RemediationInProgress {
+			t.Fatalf("phase = %s (%s), want %s in progress", rec.Phase, rec.Outcome, want)
+		}
+	}
+	result, rec, err := advance(t, newWorkflowReconciler(c, nil), now)
+	if err != nil || result != (ctrl.Result{}) {
+		t.Fatalf("advanceRemediation() = %+v, %v, want done", result, err)
+	}
+	if rec.Outcome != v1alpha1.RemediationSucceeded || rec.Message != "Health score 0.80 exceeds threshold 0.60" || rec.Progress != "drained, evicted 0 pods" {
+		t.Errorf("record = %+v, want succeeded with the decision's reason", rec)
+	}
+	if active := activeRemediations(t, c); len(active) != 0 {
+		t.Errorf("drain slots held by %v after the remediation finished", active)
+	}
+
+	var got corev1.Node
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "worker-1"}, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.Spec.Unschedulable {
+		t.Errorf("node is not cordoned")
+	}
+}
+
+func TestAdvanceRemediation_Requeue(t *testing.T) {
+	now := time.Now()
+	rec := v1alpha1.RemediationRecord{
+		Node:      "worker-1",
+		Action:    remediation.ActionCordonAndDrain,
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(now),
+		Phase:     v1alpha1.PhasePending,
+		PhaseTime: &metav1.Time{Time: now},
+	}
+	node, policy := remediatingNode(t, rec, nil)
+	c := newFakeClient(node, policy)
+	r := newWorkflowReconciler(c, nil)
+
+	// Reconciles working from a stale copy of the record repeat its step without harm.
+	var current corev1.Node
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "worker-1"}, &current); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.advanceRemediation(context.TODO(), policy.DeepCopy(), current.DeepCopy(), rec, now); err != nil {
+			t.Fatalf("advanceRemediation() error = %v", err)
+		}
+	}
+	_, got, err := advance(t, r, now)
+	if err != nil || got.Phase != v1alpha1.PhaseDraining {
+		t.Fatalf("advanceRemediation() = %v, phase %s, want Draining", err, got.Phase)
+	}
+	if active := activeRemediations(t, c); len(active) != 1 {
+		t.Errorf("drain slots = %v, want worker-1 only", active)
+	}
+}
+
+func TestAdvanceRemediation_DrainBackoff(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	node, policy := remediatingNode(t, v1alpha1.RemediationRecord{
+		Node:      "worker-1",
+		Action:    remediation.ActionCordonAndDrain,
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(now),
+		Phase:     v1alpha1.PhaseDraining,
+		PhaseTime: &metav1.Time{Time: now},
+	}, nil)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	c := newFakeClient(node, policy, pod)
+	r := newWorkflowReconciler(c, nil)
+
+	// The API server refuses to evict db-0 until its budget allows it.
+	var attempts int
+	allowed := false
+	kube := kubefake.NewSimpleClientset()
+	kube.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		attempts++
+		if !allowed {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, c.Delete(context.TODO(), pod.DeepCopy())
+	})
+	r.Remediator.KubeClient = kube
+
+	// Refused passes back off, doubling the delay, without holding the worker.
+	for i, step := range []struct {
+		at       time.Duration
+		attempts int
+		retries  int
+		requeue  time.Duration
+	}{
+		{at: 0, attempts: 1, retries: 1, requeue: time.Second},
+		{at: 500 * time.Millisecond, attempts: 1, retries: 1, requeue: 500 * time.Millisecond},
+		{at: time.Second, attempts: 2, retries: 2, requeue: 2 * time.Second},
+	} {
+		result, rec, err := advance(t, r, now.Add(step.at))
+		if err != nil {
+			t.Fatalf("pass %d: advanceRemediation() error = %v", i, err)
+		}
+		if attempts != step.attempts || result.RequeueAfter != step.requeue {
+			t.Errorf("pass %d: %d evictions, requeued after %s, want %d after %s", i, attempts, result.RequeueAfter, step.attempts, step.requeue)
+		}
+		if rec.EvictionRetries != step.retries || rec.EvictionRetryTime == nil || !strings.Contains(rec.Progress, "default/db-0 blocked") {
+			t.Errorf("pass %d: record = %+v, want %d retries and db-0 blocked", i, rec, step.retries)
+		}
+	}
+
+	allowed = true
+	_, rec, err := advance(t, r, now.Add(3*time.Second))
+	if err != nil || rec.Phase != v1alpha1.PhaseDrained {
+		t.Fatalf("advanceRemediation() = %v, record %+v, want Drained", err, rec)
+	}
+	if rec.EvictionRetries != 0 || rec.EvictionRetryTime != nil {
+		t.Errorf("record = %+v, want the retries cleared", rec)
+	}
+}
+
+func TestAdvanceRemediation_Reboot(t *testing.T) {
+	now := time.Now()
+	drained := v1alpha1.RemediationRecord{
+		Node:      "worker-1",
+		Action:    string(v1alpha1.RemediationReboot),
+		Outcome:   v1alpha1.RemediationInProgress,
+		Time:      metav1.NewTime(now),
+		Phase:     v1alpha1.PhaseDrained,
+		PhaseTime: &metav1.Time{Time: now},
+	}
+
+	t.Run("retries provider errors", func(t *testing.T) {
+		node, policy := remediatingNode(t, drained, nil)
+		c := newFakeClient(node, policy)
+		provider := cloud.NewFakeProvider()
+		provider.Err = errors.New("rate limited")
+		r := newWorkflowReconciler(c, provider)
+
+		_, rec, err := advance(t, r, now)
+		if err == nil {
+			t.Fatalf("advanceRemediation() returned no error to requeue with backoff")
+		}
+		if rec.Phase != v1alpha1.PhaseDrained || rec.Outcome != v1alpha1.RemediationInProgress || rec.BootID != "boot-1" {
+			t.Fatalf("record = %+v, want Drained in progress with boot-1", rec)
+		}
+
+		provider.Err = nil
+		result, rec, err := advance(t, r, now)
+		if err != nil || result.RequeueAfter == 0 {
+			t.Fatalf("advanceRemediation() = %+v, %v, want a poll", result, err)
+		}
+		if rec.Phase != v1alpha1.PhaseRebooting {
+			t.Fatalf("phase = %s, want Rebooting", rec.Phase)
+		}
+
+		// Requeues while the node reboots do not reboot it again.
+		if _, _, err := advance(t, r, now); err != nil {
+			t.Fatalf("advanceRemediation() error = %v", err)
+		}
+		if len(provider.Rebooted) != 1 || provider.Rebooted[0] != "i-worker-1" {
+			t.Errorf("rebooted %v, want i-worker-1 once", provider.Rebooted)
+		}
+	})
+
+	t.Run("rebooted before the phase was recorded", func(t *testing.T) {
+		rec := drained
+		rec.BootID = "boot-0"
+		node, policy := remediatingNode(t, rec, nil)
+		c := newFakeClient(node, policy)
+		provider := cloud.NewFakeProvider()
+		r := newWorkflowReconciler(c, provider)
+
+		if _, got, err := advance(t, r, now); err != nil || got.Phase != v1alpha1.PhaseRebooting {
+			t.Fatalf("advanceRemediation() = %v, phase %s, want Rebooting", err, got.Phase)
+		}
+		if len(provider.Rebooted) != 0 {
+			t.Errorf("rebooted %v again", provider.Rebooted)
+		}
+		_, got, err := advance(t, r, now)
+		if err != nil || got.Outcome != v1alpha1.RemediationSucceeded || got.Phase != v1alpha1.PhaseRebooted {
+			t.Errorf("advanceRemediation() = %v, record %+v, want Rebooted", err, got)
+		}
+	})
+
+	t.Run("no provider", func(t *testing.T) {
+		node, policy := remediatingNode(t, drained, nil)
+		c := newFakeClient(node, policy)
+
+		_, rec, err := advance(t, newWorkflowReconciler(c, nil), now)
+		if err != nil {
+			t.Fatalf("advanceRemediation() error = %v", err)
+		}
+		if rec.Outcome != v1alpha1.RemediationFailed || !strings.Contains(rec.Message, "no cloud provider configured") {
+			t.Errorf("record = %+v, want failed without a provider", rec)
+		}
+		if active := activeRemediations(t, c); len(active) != 0 {
+			t.Errorf("drain slots held by %v after the remediation failed", active)
+		}
+	})
+}
+
+// phaseProvider records the remediation phase persisted on worker-1 at each cloud provider call.
+type phaseProvider struct {
+	*cloud.FakeProvider
+	t      *testing.T
+	c      client.Client
+	phases []v1alpha1.RemediationPhase
+}
+
+func (p *phaseProvider) RebootNode(ctx context.Context, nodeID string) error {
+	p.record(ctx)
+	return p.FakeProvider.RebootNode(ctx, nodeID)
+}
+
+func (p *phaseProvider) ReplaceNode(ctx context.Context, nodeID string) error {
+	p.record(ctx)
+	return p.FakeProvider.ReplaceNode(ctx, nodeID)
+}
+
+func (p *phaseProvider) record(ctx context.Context) {
+	var node corev1.Node
+	if err := p.c.Get(ctx, client.ObjectKey{Name: "worker-1"}, &node); err != nil {
+		p.t.Fatalf("Get() error = %v", err)
+	}
+	rec, err := remediation.LastRemediation(&node)
+	if err != nil || rec == nil {
+		p.t.Fatalf("LastRemediation() = %v, %v", rec, err)
+	}
+	p.phases = append(p.phases, rec.Phase)
+}
+
+func TestAdvanceRemediation_ProviderCalls(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	drainedAt := now.Add(-time.Minute)
+	tests := []struct {
+		action string
+		phase  v1alpha1.RemediationPhase
+	}{
+		{action: string(v1alpha1.RemediationReboot), phase: v1alpha1.PhaseRebooting},
+		{action: string(v1alpha1.RemediationReplace), phase: v1alpha1.PhaseReplacing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			node, policy := remediatingNode(t, v1alpha1.RemediationRecord{
+				Node:      "worker-1",
+				Action:    tt.action,
+				Outcome:   v1alpha1.RemediationInProgress,
+				Time:      metav1.NewTime(drainedAt),
+				Phase:     v1alpha1.PhaseDrained,
+				PhaseTime: &metav1.Time{Time: drainedAt},
+			}, nil)
+			c := newFakeClient(node, policy)
+			provider := &phaseProvider{FakeProvider: cloud.NewFakeProvider(), t: t, c: c}
+			provider.Err = errors.New("rate limited")
+			r := newWorkflowReconciler(c, provider)
+
+			// A failed call goes back to Drained, keeping the phase's start, to be made again.
+			if _, rec, err := advance(t, r, now); err == nil || rec.Phase != v1alpha1.PhaseDrained || !rec.PhaseTime.Equal(&metav1.Time{Time: drainedAt}) {
+				t.Fatalf("advanceRemediation() = %v, record %+v, want an error and Drained since %v", err, rec, drainedAt)
+			}
+			provider.Err = nil
+			if _, rec, err := advance(t, r, now); err != nil || rec.Phase != tt.phase {
+				t.Fatalf("advanceRemediation() = %v, record %+v, want %s", err, rec, tt.phase)
+			}
+			// Once made, the call is not made again, e.g. after a restart.
+			if _, _, err := advance(t, r, now); err != nil {
+				t.Fatalf("advanceRemediation() error = %v", err)
+			}
+
+			// The next phase is persisted before each call.
+			if want := []v1alpha1.RemediationPhase{tt.phase, tt.phase}; !reflect.DeepEqual(provider.phases, want) {
+				t.Errorf("phases at provider calls = %v, want %v", provider.phases, want)
+			}
+			if calls := len(provider.Rebooted) + len(provider.Replaced); calls != 1 {
+				t.Errorf("provider accepted %d calls, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestAdvanceRemediation_Timeouts(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		action  string
+		phase   v1alpha1.RemediationPhase
+		elapsed time.Duration
+		want    string
+	}{
+		{
+			name:    "drain",
+			action:  remediation.ActionCordonAndDrain,
+			phase:   v1alpha1.PhaseDraining,
+			elapsed: remediation.DefaultDrainTimeout + time.Minute,
+			want:    "timed out in phase Draining after 10m0s",
+		},
+		{
+			name:    "provider calls",
+			action:  string(v1alpha1.RemediationReplace),
+			phase:   v1alpha1.PhaseDrained,
+			elapsed: remediation.DefaultDrainTimeout + time.Minute,
+			want:    "timed out in phase Drained after 10m0s",
+		},
+		{
+			name:    "reboot",
+			action:  string(v1alpha1.RemediationReboot),
+			phase:   v1alpha1.PhaseRebooting,
+			elapsed: rebootTimeout + time.Minute,
+			want:    "timed out in phase Rebooting after 15m0s",
+		},
+		{
+			name:    "replace",
+			action:  string(v1alpha1.RemediationReplace),
+			phase:   v1alpha1.PhaseReplacing,
+			elapsed: replaceTimeout + time.Minute,
+			want:    "timed out in phase Replacing after 30m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := now.Add(-tt.elapsed)
+			node, policy := remediatingNode(t, v1alpha1.RemediationRecord{
+				Node:      "worker-1",
+				Action:    tt.action,
+				Outcome:   v1alpha1.RemediationInProgress,
+				Time:      metav1.NewTime(start),
+				Phase:     tt.phase,
+				PhaseTime: &metav1.Time{Time: start},
+				BootID:    "boot-1",
+			}, nil)
+			c := newFakeClient(node, policy)
+			provider := cloud.NewFakeProvider()
+
+			_, rec, err := advance(t, newWorkflowReconciler(c, provider), now)
+			if err != nil {
+				t.Fatalf("advanceRemediation() error = %v", err)
+			}
+			if rec.Outcome != v1alpha1.RemediationFailed || rec.Phase != v1alpha1.PhaseFailed || !strings.HasPrefix(rec.Message, tt.want) {
+				t.Errorf("record = %+v, want failed with %q", rec, tt.want)
+			}
+			if active := activeRemediations(t, c); len(active) != 0 {
+				t.Errorf("drain slots held by %v after the timeout", active)
+			}
+			if len(provider.Rebooted)+len(provider.Replaced) != 0 {
+				t.Errorf("provider called after the timeout")
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package correlation finds attributes shared by nodes that degrade together.
+package correlation
+
+import (
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// Attribute is a property nodes can have in common.
+type Attribute struct {
+	// Name identifies the attribute in findings, e.g. a label key or nodeInfo.kernelVersion.
+	Name string
+	// Value extracts the attribute from a node. An empty value means the node does not have it.
+	Value func(node *corev1.Node) string
+}
+
+// LabelAttribute reads a node label.
+func LabelAttribute(key string) Attribute {
+	return Attribute{Name: key, Value: func(node *corev1.Node) string { return node.Labels[key] }}
+}
+
+// DefaultAttributes are the node properties checked for correlations: placement, hardware,
+// software versions and the labels managed node pools use to identify the pool and its image.
+var DefaultAttributes = []Attribute{
+	LabelAttribute(corev1.LabelTopologyZone),
+	LabelAttribute(corev1.LabelInstanceTypeStable),
+	{Name: "nodeInfo.kernelVersion", Value: func(n *corev1.Node) string { return n.Status.NodeInfo.KernelVersion }},
+	{Name: "nodeInfo.kubeletVersion", Value: func(n *corev1.Node) string { return n.Status.NodeInfo.KubeletVersion }},
+	{Name: "nodeInfo.osImage", Value: func(n *corev1.Node) string { return n.Status.NodeInfo.OSImage }},
+	{Name: "nodeInfo.containerRuntimeVersion", Value: func(n *corev1.Node) string { return n.Status.NodeInfo.ContainerRuntimeVersion }},
+	LabelAttribute("eks.amazonaws.com/nodegroup-image"),
+	LabelAttribute("eks.amazonaws.com/nodegroup"),
+	LabelAttribute("cloud.google.com/gke-nodepool"),
+	LabelAttribute("kubernetes.azure.com/agentpool"),
+	LabelAttribute("karpenter.sh/nodepool"),
+}
+
+// Config configures an Analyzer.
+type Config struct {
+	// Attributes overrides DefaultAttributes.
+	Attributes []Attribute
+
+	// MinNodes is the number of unhealthy nodes that must share a value before it is reported.
+	MinNodes int
+
+	// MaxPValue is the significance level: a value is over-represented when the probability of
+	// at least as many unhealthy nodes sharing it by chance is below MaxPValue.
+	MaxPValue float64
+
+	// MaxNodesListed caps the node names listed in a finding.
+	MaxNodesListed int
+}
+
+// Analyzer groups unhealthy nodes by shared attributes and flags values that are over-represented
+// compared to the rest of the pool.
+type Analyzer struct {
+	cfg Config
+}
+
+// NewAnalyzer creates an analyzer.
+func NewAnalyzer(cfg Config) *Analyzer {
+	if cfg.Attributes == nil {
+		cfg.Attributes = DefaultAttributes
+	}
+	if cfg.MinNodes <= 0 {
+		cfg.MinNodes = 2
+	}
+	if cfg.MaxPValue <= 0 {
+		cfg.MaxPValue = 0.01
+	}
+	if cfg.MaxNodesListed <= 0 {
+		cfg.MaxNodesListed = 10
+	}
+	return &Analyzer{cfg: cfg}
+}
+
+// Analyze returns the attribute values over-represented among the unhealthy nodes, most significant first.
+//
+// Under the hypothesis that degradation is unrelated to an attribute value shared by n of the
+// pool's N nodes, the number of unhealthy nodes with the value follows a hypergeometric
+// distribution. A value is flagged when seeing at least as many unhealthy nodes with it is unlikely.
+func (a *Analyzer) Analyze(nodes []corev1.Node, unhealthy map[string]bool) []v1alpha1.CorrelationFinding {
+	total := len(nodes)
+	var totalUnhealthy int
+	for i := range nodes {
+		if unhealthy[nodes[i].Name] {
+			totalUnhealthy++
+		}
+	}
+	if totalUnhealthy < a.cfg.MinNodes {
+		return nil
+	}
+
+	type group struct {
+		nodes     int
+		unhealthy []string
+	}
+	type candidate struct {
+		finding v1alpha1.CorrelationFinding
+		p       float64
+	}
+	var candidates []candidate
+	for _, attr := range a.cfg.Attributes {
+		groups := make(map[string]*group)
+		for i := range nodes {
+			value := attr.Value(&nodes[i])
+			if value == "" {
+				continue
+			}
+			g, ok := groups[value]
+			if !ok {
+				g = &group{}
+				groups[value] = g
+			}
+			g.nodes++
+			if unhealthy[nodes[i].Name] {
+				g.unhealthy = append(g.unhealthy, nodes[i].Name)
+			}
+		}
+		for value, g := range groups {
+			if len(g.unhealthy) < a.cfg.MinNodes {
+				continue
+			}
+			p := hypergeometricTail(total, g.nodes, totalUnhealthy, len(g.unhealthy))
+			if p >= a.cfg.MaxPValue {
+				continue
+			}
+			sort.Strings(g.unhealthy)
+			listed := g.unhealthy
+			if len(listed) > a.cfg.MaxNodesListed {
+				listed = listed[:a.cfg.MaxNodesListed]
+			}
+			candidates = append(candidates, candidate{
+				finding: v1alpha1.CorrelationFinding{
+					Attribute:      attr.Name,
+					Value:          value,
+					UnhealthyNodes: len(g.unhealthy),
+					Nodes:          g.nodes,
+					TotalUnhealthy: totalUnhealthy,
+					Examples:       listed,
+				},
+				p: p,
+			})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].p != candidates[j].p {
+			return candidates[i].p < candidates[j].p
+		}
+		if candidates[i].finding.Attribute != candidates[j].finding.Attribute {
+			return candidates[i].finding.Attribute < candidates[j].finding.Attribute
+		}
+		return candidates[i].finding.Value < candidates[j].finding.Value
+	})
+	findings := make([]v1alpha1.CorrelationFinding, len(candidates))
+	for i, c := range candidates {
+		findings[i] = c.finding
+	}
+	return findings
+}
+
+// hypergeometricTail is the probability of drawing at least k marked items when drawing draws
+// items without replacement from a population of size population with marked marked items.
+func hypergeometricTail(population, marked, draws, k int) float64 {
+	upper := marked
+	if draws < upper {
+		upper = draws
+	}
+	var p float64
+	for i := k; i <= upper; i++ {
+		p += math.Exp(logChoose(marked, i) + logChoose(population-marked, draws-i) - logChoose(population, draws))
+	}
+	return p
+}
+
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// FindingsFor returns the findings that apply to the node.
+func (a *Analyzer) FindingsFor(node *corev1.Node, findings []v1alpha1.CorrelationFinding) []v1alpha1.CorrelationFinding {
+	var matched []v1alpha1.CorrelationFinding
+	for _, f := range findings {
+		for _, attr := range a.cfg.Attributes {
+			if attr.Name == f.Attribute && attr.Value(node) == f.Value {
+				matched = append(matched, f)
+				break
+			}
+		}
+	}
+	return matched
+}
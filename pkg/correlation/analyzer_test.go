@@ -0,0 +1,57 @@
+package correlation
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func node(name, zone, kernel string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		},
+		Status: corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{KernelVersion: kernel}},
+	}
+}
+
+func TestAnalyzer_Analyze(t *testing.T) {
+	// 20 nodes in two zones; 5 nodes in zone a run a new kernel.
+	var nodes []corev1.Node
+	for i := 0; i < 20; i++ {
+		zone, kernel := "zone-a", "5.10"
+		if i >= 10 {
+			zone = "zone-b"
+		}
+		if i < 5 {
+			kernel = "6.1"
+		}
+		nodes = append(nodes, node(fmt.Sprintf("worker-%02d", i), zone, kernel))
+	}
+	a := NewAnalyzer(Config{})
+
+	// Four of the five new-kernel nodes degrade.
+	unhealthy := map[string]bool{"worker-00": true, "worker-01": true, "worker-02": true, "worker-03": true}
+	findings := a.Analyze(nodes, unhealthy)
+	if len(findings) == 0 {
+		t.Fatalf("Analyze() = no findings, want kernel correlation")
+	}
+	if f := findings[0]; f.Attribute != "nodeInfo.kernelVersion" || f.Value != "6.1" || f.UnhealthyNodes != 4 || f.Nodes != 5 {
+		t.Errorf("Analyze()[0] = %+v, want kernel 6.1 with 4 of 5 nodes", f)
+	}
+	if got := a.FindingsFor(&nodes[0], findings); len(got) != len(findings) {
+		t.Errorf("FindingsFor(worker-00) = %v, want all findings", got)
+	}
+	if got := a.FindingsFor(&nodes[15], findings); len(got) != 0 {
+		t.Errorf("FindingsFor(worker-15) = %v, want none", got)
+	}
+
+	// Unhealthy nodes spread evenly across zones and kernels share nothing unusual.
+	spread := map[string]bool{"worker-00": true, "worker-07": true, "worker-12": true, "worker-18": true}
+	if findings := a.Analyze(nodes, spread); len(findings) != 0 {
+		t.Errorf("Analyze() = %+v, want no findings", findings)
+	}
+}
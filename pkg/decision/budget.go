@@ -0,0 +1,50 @@
+package decision
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// PoolBudget is the current capacity of the node pool a candidate belongs to.
+type PoolBudget struct {
+	// Size is the number of nodes the pool should have, from the cloud provider when available.
+	Size int
+
+	// Unavailable is the number of pool nodes that are cordoned, NotReady or missing.
+	Unavailable int
+
+	// CandidateUnavailable is true if the candidate is already counted in Unavailable,
+	// in which case remediating it does not reduce the pool's capacity any further.
+	CandidateUnavailable bool
+}
+
+// Healthy is the number of available nodes in the pool.
+func (b PoolBudget) Healthy() int {
+	return b.Size - b.Unavailable
+}
+
+// Allows reports whether remediating the candidate keeps the pool within the policy's limits.
+func (b PoolBudget) Allows(limits v1alpha1.Limits) (bool, string) {
+	unavailable, healthy := b.Unavailable, b.Healthy()
+	if !b.CandidateUnavailable {
+		unavailable++
+		healthy--
+	}
+
+	if limits.MaxUnavailable != nil {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(limits.MaxUnavailable, b.Size, true)
+		if err != nil {
+			return false, fmt.Sprintf("invalid maxUnavailable: %v", err)
+		}
+		if unavailable > maxUnavailable {
+			return false, fmt.Sprintf("%d of %d nodes would be unavailable, more than maxUnavailable %s", unavailable, b.Size, limits.MaxUnavailable.String())
+		}
+	}
+	if healthy < limits.MinHealthyNodes {
+		return false, fmt.Sprintf("only %d of %d nodes would remain healthy, fewer than minHealthyNodes %d", healthy, b.Size, limits.MinHealthyNodes)
+	}
+	return true, ""
+}
@@ -2,6 +2,7 @@ package decision
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
@@ -13,41 +14,233 @@ const (
 	ActionNone      ActionType = "None"
 	ActionMonitor   ActionType = "Monitor"
 	ActionRemediate ActionType = "Remediate"
+
+	// ActionDeescalate steps a node back down the policy's escalation ladder.
+	ActionDeescalate ActionType = "Deescalate"
 )
 
 type Decision struct {
 	Action ActionType
 	Reason string
+
+	// Step is the ladder step to remediate or de-escalate to, empty when the policy has no ladder
+	// or the node leaves the ladder.
+	Step v1alpha1.RemediationAction
+	// StepIndex is the index of Step in the ladder, -1 when the node leaves the ladder.
+	StepIndex int
+}
+
+// TelemetryGate reports whether the telemetry behind the health scores can be trusted.
+type TelemetryGate interface {
+	// TelemetryHealthy returns false and a reason while remediation must not be based on telemetry.
+	TelemetryHealthy() (bool, string)
 }
 
 // Engine is responsible for making remediation decisions based on health scores and policies.
 // It is a stateless component that takes inputs (score, policy, history) and returns a Decision.
-type Engine struct{}
+type Engine struct {
+	// Telemetry, if set, blocks remediation while the signal upstream is unhealthy.
+	Telemetry TelemetryGate
+
+	// Systemic, if set, halts remediation while too many nodes are unhealthy at once.
+	Systemic *SystemicBreaker
+}
 
 // NewEngine creates a new decision engine.
 func NewEngine() *Engine {
 	return &Engine{}
 }
 
-// Evaluate determines the next action based on the score and policy.
+// Input is the state of a node the engine decides on.
+type Input struct {
+	// Node is the name of the node.
+	Node string
+
+	// Pool identifies the group of nodes the node is remediated with, normally its policy.
+	Pool string
+
+	// Score is the node's current health score.
+	Score float64
+
+	// Policy is the policy covering the node.
+	Policy *v1alpha1.NodeHealingPolicy
+
+	// LastRemediation is when the node or its pool was last remediated.
+	LastRemediation time.Time
+
+	// History holds the node's recent scores, oldest first, including the current one.
+	// When nil the evaluation window is not enforced and the current score alone decides.
+	History []Sample
+
+	// Correlations are the correlation findings the node is part of.
+	Correlations []v1alpha1.CorrelationFinding
+
+	// Health is the node's health state including the current score. When nil the node is
+	// unhealthy whenever its score is at or above the unhealthy threshold.
+	// The escalation ladder has hysteresis of its own and ignores it.
+	Health *HealthStatus
+
+	// Step is the node's current step on the policy's escalation ladder, nil if it is on none.
+	Step *StepState
+
+	// Budget is the capacity of the node's pool. When nil the pool disruption limits are not enforced.
+	Budget *PoolBudget
+
+	// Now is the evaluation time. Defaults to time.Now().
+	Now time.Time
+}
+
+// Evaluate determines the next action based on the instantaneous score and policy.
 func (e *Engine) Evaluate(score float64, policy *v1alpha1.NodeHealingPolicy, lastRemediationTime time.Time) Decision {
+	return e.Decide(Input{Score: score, Policy: policy, LastRemediation: lastRemediationTime})
+}
+
+// Decide determines the next action for a node.
+func (e *Engine) Decide(in Input) Decision {
+	policy := in.Policy
+	score := in.Score
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
 	threshold := policy.Spec.Thresholds.UnhealthyScore
 
-	if score < threshold {
+	unhealthy := score >= threshold
+	if in.Health != nil {
+		unhealthy = in.Health.State == v1alpha1.NodeUnhealthy
+	}
+
+	if in.Node != "" {
+		e.Systemic.Observe(in.Pool, in.Node, unhealthy, now)
+	}
+
+	if len(policy.Spec.Remediation.Ladder) > 0 {
+		return e.decideLadder(in, now)
+	}
+
+	if !unhealthy {
+		if in.Health != nil && in.Health.State != v1alpha1.NodeHealthy {
+			return Decision{
+				Action: ActionMonitor,
+				Reason: fmt.Sprintf("Node is %s since %s", strings.ToLower(string(in.Health.State)), in.Health.Since.Format(time.RFC3339)),
+			}
+		}
 		return Decision{Action: ActionNone, Reason: "Node is healthy"}
 	}
 
-	// Score >= threshold. Check cooldown.
-	cooldown := policy.Spec.Remediation.Cooldown.Duration
-	if time.Since(lastRemediationTime) < cooldown {
-		return Decision{
-			Action: ActionMonitor,
-			Reason: "Node is unhealthy but within cooldown period",
+	gated := in
+	if in.Health != nil {
+		// The health state already required the score to persist over the evaluation window,
+		// and keeps the node unhealthy until its score drops below the healthy threshold.
+		gated.History = nil
+	}
+	if reason := e.blocked(gated, threshold, "", now); reason != "" {
+		return Decision{Action: ActionMonitor, Reason: reason}
+	}
+
+	reason := fmt.Sprintf("Health score %.2f exceeds threshold %.2f", score, threshold)
+	if score < threshold {
+		reason = fmt.Sprintf("Health score %.2f has not recovered below %.2f", score, HealthyScore(policy.Spec.Thresholds))
+	}
+	return Decision{Action: ActionRemediate, Reason: reason}
+}
+
+// blocked returns why the node must not be remediated with the action, or "" if it may.
+// minScore is the score the node must have held over the evaluation window. An empty action
+// is the default cordon and drain.
+func (e *Engine) blocked(in Input, minScore float64, action v1alpha1.RemediationAction, now time.Time) string {
+	policy := in.Policy
+
+	// A score built from broken telemetry is not evidence enough to act on.
+	if healthy, reason := e.TelemetryHealthy(); !healthy {
+		return fmt.Sprintf("Node is unhealthy but telemetry is not trusted: %s", reason)
+	}
+
+	if tripped, reason := e.Systemic.Tripped(in.Pool, now); tripped {
+		return fmt.Sprintf("Node is unhealthy but remediation is halted: %s", reason)
+	}
+
+	// Replacing nodes one by one rarely fixes a shared cause such as a bad kernel or a zone outage.
+	if len(in.Correlations) > 0 && policy.Spec.Limits.SuppressCorrelated {
+		f := in.Correlations[0]
+		return fmt.Sprintf("Node is unhealthy but its degradation correlates with %s=%s (%d of %d unhealthy nodes), remediation is suppressed",
+			f.Attribute, f.Value, f.UnhealthyNodes, f.TotalUnhealthy)
+	}
+
+	// A single bad scrape is not enough; the score must persist over the evaluation window.
+	if in.History != nil {
+		if ok, reason := persisted(in.History, minScore, policy.Spec.Thresholds, now); !ok {
+			return reason
+		}
+	}
+
+	// A taint or cordon is cheap to undo, so the cooldown only spaces out evictions.
+	if Evicts(action) {
+		cooldown := policy.Spec.Remediation.Cooldown.Duration
+		if now.Sub(in.LastRemediation) < cooldown {
+			return "Node is unhealthy but within cooldown period"
 		}
 	}
 
-	return Decision{
-		Action: ActionRemediate,
-		Reason: fmt.Sprintf("Health score %.2f exceeds threshold %.2f", score, threshold),
+	// Evictions wait for a maintenance window unless the node is critical.
+	if windows := policy.Spec.Remediation.Windows; Evicts(action) && len(windows) > 0 {
+		if critical := policy.Spec.Thresholds.CriticalScore; critical <= 0 || in.Score < critical {
+			open, err := InWindow(windows, now)
+			if err != nil {
+				return fmt.Sprintf("Node is unhealthy but the maintenance windows are invalid: %v", err)
+			}
+			if !open {
+				return "Node is unhealthy but outside the maintenance windows"
+			}
+		}
+	}
+
+	// Never take the pool below a safe healthy capacity.
+	if in.Budget != nil && action != v1alpha1.RemediationTaint {
+		if ok, reason := in.Budget.Allows(policy.Spec.Limits); !ok {
+			return fmt.Sprintf("Node is unhealthy but the pool disruption budget is exhausted: %s", reason)
+		}
+	}
+	return ""
+}
+
+// TelemetryHealthy reports whether the engine's telemetry gate allows remediation.
+func (e *Engine) TelemetryHealthy() (bool, string) {
+	if e.Telemetry == nil {
+		return true, ""
+	}
+	return e.Telemetry.TelemetryHealthy()
+}
+
+// persisted reports whether the score stayed at or above minScore for the evaluation window.
+// The history must reach back to the start of the window, and the share of unhealthy samples
+// within the window must reach UnhealthyFraction (all of them by default).
+func persisted(history []Sample, minScore float64, thresholds v1alpha1.Thresholds, now time.Time) (bool, string) {
+	window := thresholds.EvaluationWindow.Duration
+	if window <= 0 {
+		return true, ""
+	}
+	start := now.Add(-window)
+	if len(history) == 0 || history[0].At.After(start) {
+		return false, fmt.Sprintf("Node is unhealthy but has not been observed for the evaluation window of %s", window)
+	}
+
+	var total, unhealthy int
+	for _, s := range history {
+		if s.At.Before(start) || s.At.After(now) {
+			continue
+		}
+		total++
+		if s.Score >= minScore {
+			unhealthy++
+		}
+	}
+	required := thresholds.UnhealthyFraction
+	if required <= 0 {
+		required = 1.0
+	}
+	if total == 0 || float64(unhealthy)/float64(total) < required {
+		return false, fmt.Sprintf("Node is unhealthy but only %d of %d samples in the last %s reach %.2f", unhealthy, total, window, minScore)
 	}
+	return true, ""
 }
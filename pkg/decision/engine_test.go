@@ -7,6 +7,7 @@ import (
 
 	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestEngine_Evaluate(t *testing.T) {
@@ -68,3 +69,488 @@ func TestEngine_Evaluate(t *testing.T) {
 		})
 	}
 }
+
+type fakeTelemetry struct {
+	healthy bool
+}
+
+func (f fakeTelemetry) TelemetryHealthy() (bool, string) {
+	return f.healthy, "prometheus circuit is Open"
+}
+
+func TestEngine_Evaluate_TelemetryGate(t *testing.T) {
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6},
+		},
+	}
+
+	e := &Engine{Telemetry: fakeTelemetry{healthy: false}}
+	if got := e.Evaluate(0.9, policy, time.Time{}); got.Action != ActionMonitor {
+		t.Errorf("Engine.Evaluate() with open circuit = %v, want %v", got, ActionMonitor)
+	}
+	if got := e.Evaluate(0.1, policy, time.Time{}); got.Action != ActionNone {
+		t.Errorf("Engine.Evaluate() for healthy node = %v, want %v", got, ActionNone)
+	}
+
+	e.Telemetry = fakeTelemetry{healthy: true}
+	if got := e.Evaluate(0.9, policy, time.Time{}); got.Action != ActionRemediate {
+		t.Errorf("Engine.Evaluate() with closed circuit = %v, want %v", got, ActionRemediate)
+	}
+}
+
+func TestEngine_Decide_EvaluationWindow(t *testing.T) {
+	now := time.Now()
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{
+				UnhealthyScore:   0.6,
+				EvaluationWindow: metav1.Duration{Duration: 5 * time.Minute},
+			},
+		},
+	}
+	// scores builds one sample per minute ending at now.
+	scores := func(values ...float64) []Sample {
+		samples := make([]Sample, len(values))
+		for i, v := range values {
+			samples[i] = Sample{At: now.Add(-time.Duration(len(values)-1-i) * time.Minute), Score: v}
+		}
+		return samples
+	}
+	fraction := policy.DeepCopy()
+	fraction.Spec.Thresholds.UnhealthyFraction = 0.8
+
+	tests := []struct {
+		name    string
+		policy  *v1alpha1.NodeHealingPolicy
+		history []Sample
+		want    ActionType
+	}{
+		{name: "Persisted for the whole window", policy: policy, history: scores(0.7, 0.7, 0.7, 0.8, 0.9, 0.7), want: ActionRemediate},
+		{name: "Not observed long enough", policy: policy, history: scores(0.9, 0.9, 0.9), want: ActionMonitor},
+		{name: "Single dip resets", policy: policy, history: scores(0.7, 0.7, 0.7, 0.2, 0.9, 0.7), want: ActionMonitor},
+		{name: "Single dip within fraction", policy: fraction, history: scores(0.7, 0.7, 0.7, 0.2, 0.9, 0.7), want: ActionRemediate},
+		{name: "Current score healthy", policy: policy, history: scores(0.7, 0.7, 0.7, 0.8, 0.9, 0.1), want: ActionNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			got := e.Decide(Input{
+				Score:   tt.history[len(tt.history)-1].Score,
+				Policy:  tt.policy,
+				History: tt.history,
+				Now:     now,
+			})
+			if got.Action != tt.want {
+				t.Errorf("Engine.Decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreHistory(t *testing.T) {
+	h := NewScoreHistory(3)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		h.Record("worker-1", start.Add(time.Duration(i)*time.Minute), float64(i))
+	}
+
+	got := h.Samples("worker-1")
+	want := []float64{2, 3, 4}
+	if len(got) != len(want) {
+		t.Fatalf("Samples() = %v, want scores %v", got, want)
+	}
+	for i := range want {
+		if got[i].Score != want[i] {
+			t.Errorf("Samples()[%d] = %v, want %v", i, got[i].Score, want[i])
+		}
+	}
+
+	h.Forget("worker-1")
+	if got := h.Samples("worker-1"); got != nil {
+		t.Errorf("Samples() after Forget = %v, want nil", got)
+	}
+}
+
+func TestEngine_Decide_PoolBudget(t *testing.T) {
+	percent := intstr.FromString("20%")
+	absolute := intstr.FromInt(1)
+	policy := func(limits v1alpha1.Limits) *v1alpha1.NodeHealingPolicy {
+		return &v1alpha1.NodeHealingPolicy{
+			Spec: v1alpha1.NodeHealingPolicySpec{
+				Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6},
+				Limits:     limits,
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		limits v1alpha1.Limits
+		budget PoolBudget
+		want   ActionType
+	}{
+		{name: "Within max unavailable", limits: v1alpha1.Limits{MaxUnavailable: &absolute}, budget: PoolBudget{Size: 5}, want: ActionRemediate},
+		{name: "Max unavailable reached", limits: v1alpha1.Limits{MaxUnavailable: &absolute}, budget: PoolBudget{Size: 5, Unavailable: 1}, want: ActionMonitor},
+		{name: "Candidate already unavailable", limits: v1alpha1.Limits{MaxUnavailable: &absolute}, budget: PoolBudget{Size: 5, Unavailable: 1, CandidateUnavailable: true}, want: ActionRemediate},
+		{name: "Percentage rounds up", limits: v1alpha1.Limits{MaxUnavailable: &percent}, budget: PoolBudget{Size: 3}, want: ActionRemediate},
+		{name: "Percentage reached", limits: v1alpha1.Limits{MaxUnavailable: &percent}, budget: PoolBudget{Size: 10, Unavailable: 2}, want: ActionMonitor},
+		{name: "Min healthy nodes kept", limits: v1alpha1.Limits{MinHealthyNodes: 3}, budget: PoolBudget{Size: 5, Unavailable: 1}, want: ActionRemediate},
+		{name: "Min healthy nodes reached", limits: v1alpha1.Limits{MinHealthyNodes: 3}, budget: PoolBudget{Size: 4, Unavailable: 1}, want: ActionMonitor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			budget := tt.budget
+			got := e.Decide(Input{Score: 0.9, Policy: policy(tt.limits), Budget: &budget})
+			if got.Action != tt.want {
+				t.Errorf("Engine.Decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Decide_SystemicBreaker(t *testing.T) {
+	now := time.Now()
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6},
+		},
+	}
+	e := &Engine{Systemic: NewSystemicBreaker(SystemicBreakerConfig{
+		MaxUnhealthyFraction: 0.3,
+		MinUnhealthyNodes:    2,
+		Window:               10 * time.Minute,
+	})}
+	decide := func(node string, score float64, at time.Time) Decision {
+		return e.Decide(Input{Node: node, Pool: "workers", Score: score, Policy: policy, Now: at})
+	}
+
+	for _, node := range []string{"worker-1", "worker-2", "worker-3", "worker-4", "worker-5"} {
+		decide(node, 0.1, now)
+	}
+	if got := decide("worker-1", 0.9, now); got.Action != ActionRemediate {
+		t.Fatalf("single unhealthy node = %v, want %v", got, ActionRemediate)
+	}
+	if got := decide("worker-2", 0.9, now); got.Action != ActionMonitor {
+		t.Fatalf("2 of 5 unhealthy nodes = %v, want %v", got, ActionMonitor)
+	}
+	if tripped, _ := e.Systemic.Tripped("other", now); !tripped {
+		t.Errorf("Tripped() for another pool = false, want cluster-wide trip")
+	}
+
+	// Once the other node recovers the breaker resets.
+	decide("worker-2", 0.1, now.Add(time.Minute))
+	if got := decide("worker-1", 0.9, now.Add(time.Minute)); got.Action != ActionRemediate {
+		t.Errorf("after recovery = %v, want %v", got, ActionRemediate)
+	}
+}
+
+func TestEngine_Decide_Correlated(t *testing.T) {
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6},
+		},
+	}
+	findings := []v1alpha1.CorrelationFinding{{Attribute: "topology.kubernetes.io/zone", Value: "zone-a", UnhealthyNodes: 4, Nodes: 5, TotalUnhealthy: 4}}
+
+	e := &Engine{}
+	if got := e.Decide(Input{Score: 0.9, Policy: policy, Correlations: findings}); got.Action != ActionRemediate {
+		t.Errorf("Engine.Decide() without suppression = %v, want %v", got, ActionRemediate)
+	}
+	policy.Spec.Limits.SuppressCorrelated = true
+	if got := e.Decide(Input{Score: 0.9, Policy: policy, Correlations: findings}); got.Action != ActionMonitor {
+		t.Errorf("Engine.Decide() with suppression = %v, want %v", got, ActionMonitor)
+	}
+}
+
+func TestEngine_Decide_Ladder(t *testing.T) {
+	now := time.Now()
+	budget := PoolBudget{Size: 5, Unavailable: 1}
+	one := intstr.FromInt(1)
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.7},
+			Remediation: v1alpha1.Remediation{
+				Cooldown: metav1.Duration{Duration: time.Hour},
+				Ladder: []v1alpha1.RemediationStep{
+					{Action: v1alpha1.RemediationTaint, MinScore: 0.4},
+					{Action: v1alpha1.RemediationCordon, MinScore: 0.6},
+					{Action: v1alpha1.RemediationDrain, MinScore: 0.7},
+					{Action: v1alpha1.RemediationReplace, MinScore: 0.6, After: metav1.Duration{Duration: 30 * time.Minute}},
+				},
+			},
+			Limits: v1alpha1.Limits{MaxUnavailable: &one},
+		},
+	}
+	at := func(index int, ago time.Duration) *StepState {
+		return &StepState{Index: index, Since: now.Add(-ago)}
+	}
+
+	tests := []struct {
+		name      string
+		score     float64
+		step      *StepState
+		last      time.Time
+		budget    *PoolBudget
+		history   []Sample
+		ladder    []v1alpha1.RemediationStep
+		want      ActionType
+		wantStep  v1alpha1.RemediationAction
+		wantIndex int
+	}{
+		{name: "Healthy", score: 0.2, want: ActionNone, wantIndex: -1},
+		{name: "Taint", score: 0.45, want: ActionRemediate, wantStep: v1alpha1.RemediationTaint, wantIndex: 0},
+		{name: "Skips to drain", score: 0.8, want: ActionRemediate, wantStep: v1alpha1.RemediationDrain, wantIndex: 2},
+		{name: "Stays on step", score: 0.65, step: at(1, time.Minute), want: ActionMonitor, wantStep: v1alpha1.RemediationCordon, wantIndex: 1},
+		{name: "Taint ignores budget and cooldown", score: 0.45, last: now, budget: &budget, want: ActionRemediate, wantStep: v1alpha1.RemediationTaint, wantIndex: 0},
+		{name: "Cordon ignores cooldown", score: 0.65, step: at(0, time.Minute), last: now, want: ActionRemediate, wantStep: v1alpha1.RemediationCordon, wantIndex: 1},
+		{name: "Cordon respects budget", score: 0.65, step: at(0, time.Minute), budget: &budget, want: ActionMonitor, wantStep: v1alpha1.RemediationTaint, wantIndex: 0},
+		{name: "Drain respects cooldown", score: 0.8, step: at(1, time.Minute), last: now, want: ActionMonitor, wantStep: v1alpha1.RemediationCordon, wantIndex: 1},
+		{name: "Replace after drain", score: 0.65, step: at(2, 40*time.Minute), want: ActionRemediate, wantStep: v1alpha1.RemediationReplace, wantIndex: 3},
+		{name: "Replace holds drain", score: 0.65, step: at(2, 10*time.Minute), want: ActionMonitor, wantStep: v1alpha1.RemediationDrain, wantIndex: 2},
+		{name: "Drain recovers", score: 0.5, step: at(2, 10*time.Minute), want: ActionDeescalate, wantStep: v1alpha1.RemediationTaint, wantIndex: 0},
+		{name: "Replace needs previous step", score: 0.65, step: at(1, 40*time.Minute), want: ActionMonitor, wantStep: v1alpha1.RemediationCordon, wantIndex: 1},
+		{name: "De-escalate to taint", score: 0.45, step: at(1, time.Minute), want: ActionDeescalate, wantStep: v1alpha1.RemediationTaint, wantIndex: 0},
+		{name: "De-escalate off the ladder", score: 0.1, step: at(2, time.Minute), want: ActionDeescalate, wantIndex: -1},
+		{
+			name:  "Escalation must persist",
+			score: 0.8, step: at(1, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.8}, {At: now.Add(-3 * time.Minute), Score: 0.65}, {At: now, Score: 0.8}},
+			want:      ActionMonitor,
+			wantStep:  v1alpha1.RemediationCordon,
+			wantIndex: 1,
+		},
+		{
+			name:      "Taint persists below the unhealthy threshold",
+			score:     0.45,
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.45}, {At: now.Add(-3 * time.Minute), Score: 0.5}, {At: now, Score: 0.45}},
+			want:      ActionRemediate,
+			wantStep:  v1alpha1.RemediationTaint,
+			wantIndex: 0,
+		},
+		{
+			name:  "Cordon persists below the unhealthy threshold",
+			score: 0.65, step: at(0, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.62}, {At: now.Add(-3 * time.Minute), Score: 0.65}, {At: now, Score: 0.65}},
+			want:      ActionRemediate,
+			wantStep:  v1alpha1.RemediationCordon,
+			wantIndex: 1,
+		},
+		{
+			name:  "Critical step needs its own score to persist",
+			score: 0.95, step: at(1, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.95}, {At: now.Add(-3 * time.Minute), Score: 0.92}, {At: now, Score: 0.95}},
+			ladder:    []v1alpha1.RemediationStep{{Action: v1alpha1.RemediationDrain, MinScore: 0.7}, {Action: v1alpha1.RemediationReboot, MinScore: 0.8}, {Action: v1alpha1.RemediationReplace, MinScore: 0.9}},
+			want:      ActionRemediate,
+			wantStep:  v1alpha1.RemediationReplace,
+			wantIndex: 2,
+		},
+		{
+			name:  "Critical step not persisted",
+			score: 0.95, step: at(1, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.95}, {At: now.Add(-3 * time.Minute), Score: 0.85}, {At: now, Score: 0.95}},
+			ladder:    []v1alpha1.RemediationStep{{Action: v1alpha1.RemediationDrain, MinScore: 0.7}, {Action: v1alpha1.RemediationReboot, MinScore: 0.8}, {Action: v1alpha1.RemediationReplace, MinScore: 0.9}},
+			want:      ActionMonitor,
+			wantStep:  v1alpha1.RemediationReboot,
+			wantIndex: 1,
+		},
+		{
+			name:  "Recovery must persist",
+			score: 0.3, step: at(1, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.3}, {At: now.Add(-2 * time.Minute), Score: 0.62}, {At: now, Score: 0.3}},
+			want:      ActionMonitor,
+			wantStep:  v1alpha1.RemediationCordon,
+			wantIndex: 1,
+		},
+		{
+			name:  "Recovered over window",
+			score: 0.3, step: at(1, time.Minute),
+			history:   []Sample{{At: now.Add(-10 * time.Minute), Score: 0.3}, {At: now.Add(-2 * time.Minute), Score: 0.5}, {At: now, Score: 0.3}},
+			want:      ActionDeescalate,
+			wantIndex: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy.DeepCopy()
+			if tt.history != nil {
+				p.Spec.Thresholds.EvaluationWindow = metav1.Duration{Duration: 5 * time.Minute}
+			}
+			if tt.ladder != nil {
+				p.Spec.Remediation.Ladder = tt.ladder
+			}
+			e := &Engine{}
+			got := e.Decide(Input{
+				Score:           tt.score,
+				Policy:          p,
+				LastRemediation: tt.last,
+				History:         tt.history,
+				Step:            tt.step,
+				Budget:          tt.budget,
+				Now:             now,
+			})
+			if got.Action != tt.want || got.Step != tt.wantStep || got.StepIndex != tt.wantIndex {
+				t.Errorf("Engine.Decide() = %+v, want %v to %q (%d)", got, tt.want, tt.wantStep, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestNextHealth(t *testing.T) {
+	now := time.Now()
+	thresholds := v1alpha1.Thresholds{
+		UnhealthyScore:   0.6,
+		HealthyScore:     0.4,
+		EvaluationWindow: metav1.Duration{Duration: 5 * time.Minute},
+		RecoveryWindow:   metav1.Duration{Duration: 10 * time.Minute},
+	}
+	persisting := []Sample{{At: now.Add(-6 * time.Minute), Score: 0.7}, {At: now, Score: 0.7}}
+	since := now.Add(-time.Minute)
+	state := func(s v1alpha1.NodeHealthState, at time.Time) HealthStatus {
+		return HealthStatus{State: s, Since: at}
+	}
+
+	tests := []struct {
+		name    string
+		prev    HealthStatus
+		score   float64
+		history []Sample
+		want    HealthStatus
+	}{
+		{name: "Unknown is healthy", score: 0.2, want: state(v1alpha1.NodeHealthy, now)},
+		{name: "Healthy stays", prev: state(v1alpha1.NodeHealthy, since), score: 0.5, want: state(v1alpha1.NodeHealthy, since)},
+		{name: "Degraded until persisted", prev: state(v1alpha1.NodeHealthy, since), score: 0.7, history: []Sample{{At: now, Score: 0.7}}, want: state(v1alpha1.NodeDegraded, now)},
+		{name: "Degraded holds between thresholds", prev: state(v1alpha1.NodeDegraded, since), score: 0.5, history: []Sample{{At: now, Score: 0.5}}, want: state(v1alpha1.NodeDegraded, since)},
+		{name: "Degraded clears below healthy", prev: state(v1alpha1.NodeDegraded, since), score: 0.3, want: state(v1alpha1.NodeHealthy, now)},
+		{name: "Unhealthy once persisted", prev: state(v1alpha1.NodeDegraded, since), score: 0.7, history: persisting, want: state(v1alpha1.NodeUnhealthy, now)},
+		{name: "Unhealthy without history", score: 0.7, want: state(v1alpha1.NodeUnhealthy, now)},
+		{name: "Unhealthy holds between thresholds", prev: state(v1alpha1.NodeUnhealthy, since), score: 0.5, want: state(v1alpha1.NodeUnhealthy, since)},
+		{name: "Recovering below healthy", prev: state(v1alpha1.NodeUnhealthy, since), score: 0.3, want: state(v1alpha1.NodeRecovering, now)},
+		{name: "Recovering relapses", prev: state(v1alpha1.NodeRecovering, since), score: 0.45, want: state(v1alpha1.NodeUnhealthy, now)},
+		{name: "Recovering waits", prev: state(v1alpha1.NodeRecovering, now.Add(-5*time.Minute)), score: 0.3, want: state(v1alpha1.NodeRecovering, now.Add(-5*time.Minute))},
+		{name: "Recovered after window", prev: state(v1alpha1.NodeRecovering, now.Add(-10*time.Minute)), score: 0.3, want: state(v1alpha1.NodeHealthy, now)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextHealth(tt.prev, tt.score, tt.history, thresholds, now)
+			if got.State != tt.want.State || !got.Since.Equal(tt.want.Since) {
+				t.Errorf("NextHealth() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Decide_Hysteresis(t *testing.T) {
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6, HealthyScore: 0.4},
+		},
+	}
+	tests := []struct {
+		name  string
+		state v1alpha1.NodeHealthState
+		score float64
+		want  ActionType
+	}{
+		{name: "Healthy", state: v1alpha1.NodeHealthy, score: 0.2, want: ActionNone},
+		{name: "Degraded", state: v1alpha1.NodeDegraded, score: 0.7, want: ActionMonitor},
+		{name: "Unhealthy between thresholds", state: v1alpha1.NodeUnhealthy, score: 0.5, want: ActionRemediate},
+		{name: "Recovering", state: v1alpha1.NodeRecovering, score: 0.3, want: ActionMonitor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			got := e.Decide(Input{Score: tt.score, Policy: policy, Health: &HealthStatus{State: tt.state}})
+			if got.Action != tt.want {
+				t.Errorf("Engine.Decide() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	// 2024-06-03 is a Monday.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2024, 6, 3, hour, minute, 0, 0, time.UTC)
+	}
+	nightly := v1alpha1.MaintenanceWindow{Days: []v1alpha1.Weekday{"Monday"}, Start: "22:00", End: "06:00"}
+
+	tests := []struct {
+		name    string
+		windows []v1alpha1.MaintenanceWindow
+		at      time.Time
+		want    bool
+		wantErr bool
+	}{
+		{name: "No windows", at: monday(12, 0), want: true},
+		{name: "Inside", windows: []v1alpha1.MaintenanceWindow{{Start: "09:00", End: "17:00"}}, at: monday(12, 0), want: true},
+		{name: "End is exclusive", windows: []v1alpha1.MaintenanceWindow{{Start: "09:00", End: "17:00"}}, at: monday(17, 0), want: false},
+		{name: "Other day", windows: []v1alpha1.MaintenanceWindow{{Days: []v1alpha1.Weekday{"Tuesday"}, Start: "09:00", End: "17:00"}}, at: monday(12, 0), want: false},
+		{name: "Past midnight, evening", windows: []v1alpha1.MaintenanceWindow{nightly}, at: monday(23, 0), want: true},
+		{name: "Past midnight, next morning", windows: []v1alpha1.MaintenanceWindow{nightly}, at: monday(23, 0).Add(6 * time.Hour), want: true},
+		{name: "Past midnight, previous morning", windows: []v1alpha1.MaintenanceWindow{nightly}, at: monday(3, 0), want: false},
+		{name: "Whole day", windows: []v1alpha1.MaintenanceWindow{{Days: []v1alpha1.Weekday{"Monday"}, Start: "00:00", End: "00:00"}}, at: monday(15, 0), want: true},
+		// 20:30 UTC is 22:30 in Berlin during summer time.
+		{name: "Time zone", windows: []v1alpha1.MaintenanceWindow{{Start: "22:00", End: "23:00", TimeZone: berlin.String()}}, at: monday(20, 30), want: true},
+		{name: "Invalid", windows: []v1alpha1.MaintenanceWindow{{Start: "25:00", End: "06:00"}}, at: monday(12, 0), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InWindow(tt.windows, tt.at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("InWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Decide_MaintenanceWindows(t *testing.T) {
+	// 2024-06-03 12:00 UTC is a Monday during business hours.
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	policy := &v1alpha1.NodeHealingPolicy{
+		Spec: v1alpha1.NodeHealingPolicySpec{
+			Thresholds: v1alpha1.Thresholds{UnhealthyScore: 0.6, CriticalScore: 0.9},
+			Remediation: v1alpha1.Remediation{
+				Windows: []v1alpha1.MaintenanceWindow{{Start: "22:00", End: "06:00"}},
+			},
+		},
+	}
+	ladder := policy.DeepCopy()
+	ladder.Spec.Remediation.Ladder = []v1alpha1.RemediationStep{
+		{Action: v1alpha1.RemediationTaint, MinScore: 0.4},
+		{Action: v1alpha1.RemediationDrain, MinScore: 0.6},
+	}
+
+	tests := []struct {
+		name     string
+		policy   *v1alpha1.NodeHealingPolicy
+		score    float64
+		now      time.Time
+		want     ActionType
+		wantStep v1alpha1.RemediationAction
+	}{
+		{name: "Outside window", policy: policy, score: 0.7, now: now, want: ActionMonitor},
+		{name: "Inside window", policy: policy, score: 0.7, now: now.Add(11 * time.Hour), want: ActionRemediate},
+		{name: "Critical overrides window", policy: policy, score: 0.95, now: now, want: ActionRemediate},
+		{name: "Taint outside window", policy: ladder, score: 0.5, now: now, want: ActionRemediate, wantStep: v1alpha1.RemediationTaint},
+		{name: "Drain falls back to taint outside window", policy: ladder, score: 0.7, now: now, want: ActionRemediate, wantStep: v1alpha1.RemediationTaint},
+		{name: "Drain inside window", policy: ladder, score: 0.7, now: now.Add(11 * time.Hour), want: ActionRemediate, wantStep: v1alpha1.RemediationDrain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{}
+			got := e.Decide(Input{Score: tt.score, Policy: tt.policy, Now: tt.now})
+			if got.Action != tt.want || got.Step != tt.wantStep {
+				t.Errorf("Engine.Decide() = %v, want %v to %q", got, tt.want, tt.wantStep)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package decision
+
+import (
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// HealthStatus is a node's health state and when it entered it.
+type HealthStatus struct {
+	State v1alpha1.NodeHealthState
+	Since time.Time
+}
+
+// NextHealth moves a node's health state on given its current score and history, oldest first.
+// A node becomes unhealthy once its score persisted at or above UnhealthyScore over the
+// evaluation window and only becomes healthy again after its score stayed below HealthyScore for
+// the recovery window. A zero prev is healthy.
+func NextHealth(prev HealthStatus, score float64, history []Sample, thresholds v1alpha1.Thresholds, now time.Time) HealthStatus {
+	healthy := HealthyScore(thresholds)
+	enter := func(state v1alpha1.NodeHealthState) HealthStatus {
+		if prev.State == state {
+			return prev
+		}
+		return HealthStatus{State: state, Since: now}
+	}
+
+	switch prev.State {
+	case v1alpha1.NodeUnhealthy:
+		if score < healthy {
+			return enter(v1alpha1.NodeRecovering)
+		}
+		return prev
+	case v1alpha1.NodeRecovering:
+		if score >= healthy {
+			return enter(v1alpha1.NodeUnhealthy)
+		}
+		window := thresholds.RecoveryWindow.Duration
+		if window <= 0 {
+			window = thresholds.EvaluationWindow.Duration
+		}
+		if now.Sub(prev.Since) >= window {
+			return enter(v1alpha1.NodeHealthy)
+		}
+		return prev
+	default:
+		// A degraded node is held between the thresholds like an unhealthy one.
+		if score < thresholds.UnhealthyScore && (prev.State != v1alpha1.NodeDegraded || score < healthy) {
+			return enter(v1alpha1.NodeHealthy)
+		}
+		if history != nil {
+			if ok, _ := persisted(history, thresholds.UnhealthyScore, thresholds, now); !ok {
+				return enter(v1alpha1.NodeDegraded)
+			}
+		}
+		return enter(v1alpha1.NodeUnhealthy)
+	}
+}
+
+// HealthyScore returns the score below which an unhealthy node recovers, which is never above
+// the unhealthy threshold.
+func HealthyScore(thresholds v1alpha1.Thresholds) float64 {
+	if thresholds.HealthyScore <= 0 || thresholds.HealthyScore > thresholds.UnhealthyScore {
+		return thresholds.UnhealthyScore
+	}
+	return thresholds.HealthyScore
+}
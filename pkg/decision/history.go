@@ -0,0 +1,96 @@
+package decision
+
+import (
+	"sync"
+	"time"
+)
+
+// Sample is a health score observed at a point in time.
+type Sample struct {
+	At    time.Time
+	Score float64
+}
+
+// ScoreHistory keeps the most recent scores of every node in a fixed-size ring buffer.
+// It is safe for concurrent use.
+type ScoreHistory struct {
+	capacity int
+
+	mu    sync.Mutex
+	nodes map[string]*scoreRing
+}
+
+type scoreRing struct {
+	samples []Sample
+	next    int
+}
+
+// NewScoreHistory creates a history that retains up to capacity samples per node.
+// The capacity must cover the longest evaluation window at the evaluation interval,
+// otherwise the window can never be satisfied.
+func NewScoreHistory(capacity int) *ScoreHistory {
+	if capacity <= 0 {
+		capacity = 128
+	}
+	return &ScoreHistory{
+		capacity: capacity,
+		nodes:    make(map[string]*scoreRing),
+	}
+}
+
+// Record appends a score for the node, overwriting the oldest sample once the buffer is full.
+func (h *ScoreHistory) Record(nodeName string, at time.Time, score float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.nodes[nodeName]
+	if !ok {
+		r = &scoreRing{samples: make([]Sample, 0, h.capacity)}
+		h.nodes[nodeName] = r
+	}
+	s := Sample{At: at, Score: score}
+	if len(r.samples) < h.capacity {
+		r.samples = append(r.samples, s)
+		return
+	}
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % h.capacity
+}
+
+// Samples returns a copy of the node's retained samples, oldest first.
+func (h *ScoreHistory) Samples(nodeName string) []Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.nodes[nodeName]
+	if !ok {
+		return nil
+	}
+	out := make([]Sample, 0, len(r.samples))
+	out = append(out, r.samples[r.next:]...)
+	out = append(out, r.samples[:r.next]...)
+	return out
+}
+
+// Latest returns the node's most recent sample.
+func (h *ScoreHistory) Latest(nodeName string) (Sample, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.nodes[nodeName]
+	if !ok || len(r.samples) == 0 {
+		return Sample{}, false
+	}
+	i := r.next - 1
+	if i < 0 {
+		i = len(r.samples) - 1
+	}
+	return r.samples[i], true
+}
+
+// Forget drops the history of a node, e.g. after it was deleted or replaced.
+func (h *ScoreHistory) Forget(nodeName string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.nodes, nodeName)
+}
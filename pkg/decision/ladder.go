@@ -0,0 +1,162 @@
+package decision
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// StepState is the step of the escalation ladder a node is on.
+type StepState struct {
+	// Index is the position of the step in the policy's ladder.
+	Index int
+
+	// Since is when the node was escalated or de-escalated to the step.
+	Since time.Time
+}
+
+// Candidate reports whether the score is high enough for the policy to act on the node,
+// either by the unhealthy threshold or by any step of its ladder.
+func Candidate(policy *v1alpha1.NodeHealingPolicy, score float64) bool {
+	if score >= policy.Spec.Thresholds.UnhealthyScore {
+		return true
+	}
+	for _, step := range policy.Spec.Remediation.Ladder {
+		if score >= step.MinScore {
+			return true
+		}
+	}
+	return false
+}
+
+// decideLadder moves the node along the policy's escalation ladder. The node climbs to the highest
+// step whose MinScore its score reaches and whose After delay it served on the previous step,
+// subject to the same gates as a plain remediation. It steps back down once its score stayed
+// below its current step for the evaluation window.
+func (e *Engine) decideLadder(in Input, now time.Time) Decision {
+	ladder := in.Policy.Spec.Remediation.Ladder
+	current := -1
+	var since time.Time
+	if in.Step != nil {
+		// The ladder may have been shortened since the node climbed it.
+		current = min(in.Step.Index, len(ladder)-1)
+		since = in.Step.Since
+	}
+
+	target := -1
+	eligible := make([]bool, len(ladder))
+	for i, step := range ladder {
+		if in.Score < step.MinScore {
+			continue
+		}
+		if step.After.Duration > 0 && current < i && (current != i-1 || now.Sub(since) < step.After.Duration) {
+			continue
+		}
+		eligible[i] = true
+		target = i
+	}
+
+	// A node waiting out the After delay of the next step holds its step while it is still bad
+	// enough for that step, so e.g. a drained node is replaced if it stays degraded.
+	hold := 0.0
+	if current >= 0 {
+		hold = ladder[current].MinScore
+		if next := current + 1; next < len(ladder) && ladder[next].After.Duration > 0 {
+			hold = min(hold, ladder[next].MinScore)
+		}
+		if target < current && in.Score >= hold {
+			target = current
+		}
+	}
+
+	switch {
+	case target > current:
+		// If the highest step is blocked, e.g. outside a maintenance window, a lower one may not be.
+		var reason string
+		for i := target; i > current; i-- {
+			if !eligible[i] {
+				continue
+			}
+			step := ladder[i]
+			blocked := e.blocked(in, step.MinScore, step.Action, now)
+			if blocked == "" {
+				return Decision{
+					Action:    ActionRemediate,
+					Reason:    fmt.Sprintf("Health score %.2f reaches the %s step threshold %.2f", in.Score, step.Action, step.MinScore),
+					Step:      step.Action,
+					StepIndex: i,
+				}
+			}
+			if reason == "" {
+				reason = blocked
+			}
+		}
+		return Decision{Action: ActionMonitor, Reason: reason, Step: stepAction(ladder, current), StepIndex: current}
+	case target < current:
+		// Undoing a step on a score from broken telemetry could be as wrong as taking one.
+		if healthy, reason := e.TelemetryHealthy(); !healthy {
+			return Decision{
+				Action:    ActionMonitor,
+				Reason:    fmt.Sprintf("Node may have recovered but telemetry is not trusted: %s", reason),
+				Step:      ladder[current].Action,
+				StepIndex: current,
+			}
+		}
+		if in.History != nil {
+			if ok, reason := recovered(in.History, hold, in.Policy.Spec.Thresholds.EvaluationWindow.Duration, now); !ok {
+				return Decision{Action: ActionMonitor, Reason: reason, Step: ladder[current].Action, StepIndex: current}
+			}
+		}
+		return Decision{
+			Action:    ActionDeescalate,
+			Reason:    fmt.Sprintf("Health score %.2f is below the %s step threshold %.2f", in.Score, ladder[current].Action, hold),
+			Step:      stepAction(ladder, target),
+			StepIndex: target,
+		}
+	case current < 0:
+		return Decision{Action: ActionNone, Reason: "Node is healthy", StepIndex: -1}
+	default:
+		return Decision{
+			Action:    ActionMonitor,
+			Reason:    fmt.Sprintf("Node remains on the %s step", ladder[current].Action),
+			Step:      ladder[current].Action,
+			StepIndex: current,
+		}
+	}
+}
+
+// stepAction returns the action of the ladder step at index, or "" for no step.
+func stepAction(ladder []v1alpha1.RemediationStep, index int) v1alpha1.RemediationAction {
+	if index < 0 {
+		return ""
+	}
+	return ladder[index].Action
+}
+
+// Evicts reports whether the action evicts the node's pods. An empty action is the default
+// cordon and drain.
+func Evicts(action v1alpha1.RemediationAction) bool {
+	return action != v1alpha1.RemediationTaint && action != v1alpha1.RemediationCordon
+}
+
+// recovered reports whether every score in the evaluation window stayed below maxScore.
+// The history must reach back to the start of the window.
+func recovered(history []Sample, maxScore float64, window time.Duration, now time.Time) (bool, string) {
+	if window <= 0 {
+		return true, ""
+	}
+	start := now.Add(-window)
+	if len(history) == 0 || history[0].At.After(start) {
+		return false, fmt.Sprintf("Node may have recovered but has not been observed for the evaluation window of %s", window)
+	}
+	for _, s := range history {
+		if s.At.Before(start) || s.At.After(now) {
+			continue
+		}
+		if s.Score >= maxScore {
+			return false, fmt.Sprintf("Node may have recovered but scored %.2f within the last %s", s.Score, window)
+		}
+	}
+	return true, ""
+}
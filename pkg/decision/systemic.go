@@ -0,0 +1,137 @@
+package decision
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SystemicBreakerConfig configures a SystemicBreaker.
+type SystemicBreakerConfig struct {
+	// MaxUnhealthyFraction trips the breaker when more than this share of the observed nodes is unhealthy.
+	MaxUnhealthyFraction float64
+
+	// MinUnhealthyNodes is the number of unhealthy nodes below which the breaker never trips,
+	// so a single bad node in a small pool is still remediated.
+	MinUnhealthyNodes int
+
+	// Window is how long an observation counts. It must be longer than the evaluation interval
+	// so every covered node is observed within it.
+	Window time.Duration
+}
+
+// SystemicBreaker halts remediation when too many nodes look unhealthy at the same time.
+// A region-wide network issue or a broken metric makes every node look bad; replacing them
+// all would only make things worse. It tracks every pool separately and the cluster as a whole.
+// It is safe for concurrent use.
+type SystemicBreaker struct {
+	cfg SystemicBreakerConfig
+
+	mu     sync.Mutex
+	scopes map[string]*systemicScope
+}
+
+// clusterScope aggregates the observations of all pools.
+const clusterScope = ""
+
+type systemicScope struct {
+	nodes map[string]nodeObservation
+	trip  *systemicTrip
+}
+
+type nodeObservation struct {
+	at        time.Time
+	unhealthy bool
+}
+
+type systemicTrip struct {
+	since     time.Time
+	unhealthy int
+	observed  int
+}
+
+// NewSystemicBreaker creates a breaker.
+func NewSystemicBreaker(cfg SystemicBreakerConfig) *SystemicBreaker {
+	if cfg.MaxUnhealthyFraction <= 0 {
+		cfg.MaxUnhealthyFraction = 0.3
+	}
+	if cfg.MinUnhealthyNodes <= 0 {
+		cfg.MinUnhealthyNodes = 3
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 10 * time.Minute
+	}
+	return &SystemicBreaker{
+		cfg:    cfg,
+		scopes: make(map[string]*systemicScope),
+	}
+}
+
+// Observe records whether the node in the pool is currently unhealthy.
+func (b *SystemicBreaker) Observe(pool, nodeName string, unhealthy bool, at time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range []string{clusterScope, "pool/" + pool} {
+		s, ok := b.scopes[name]
+		if !ok {
+			s = &systemicScope{nodes: make(map[string]nodeObservation)}
+			b.scopes[name] = s
+		}
+		s.nodes[nodeName] = nodeObservation{at: at, unhealthy: unhealthy}
+		b.evaluate(s, at)
+	}
+}
+
+// Tripped reports whether remediation in the pool is halted, either because of the pool itself
+// or because the whole cluster looks unhealthy, and explains why.
+func (b *SystemicBreaker) Tripped(pool string, now time.Time) (bool, string) {
+	if b == nil {
+		return false, ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.scopes[clusterScope]; ok {
+		if t := b.evaluate(s, now); t != nil {
+			return true, fmt.Sprintf("%d of %d nodes in the cluster are unhealthy since %s; the problem looks systemic rather than node-local",
+				t.unhealthy, t.observed, t.since.Format(time.RFC3339))
+		}
+	}
+	if s, ok := b.scopes["pool/"+pool]; ok {
+		if t := b.evaluate(s, now); t != nil {
+			return true, fmt.Sprintf("%d of %d nodes in pool %s are unhealthy since %s; the problem looks systemic rather than node-local",
+				t.unhealthy, t.observed, pool, t.since.Format(time.RFC3339))
+		}
+	}
+	return false, ""
+}
+
+// evaluate drops expired observations and trips or resets the scope. It returns the active trip, if any.
+func (b *SystemicBreaker) evaluate(s *systemicScope, now time.Time) *systemicTrip {
+	cutoff := now.Add(-b.cfg.Window)
+	var observed, unhealthy int
+	for name, o := range s.nodes {
+		if o.at.Before(cutoff) {
+			delete(s.nodes, name)
+			continue
+		}
+		observed++
+		if o.unhealthy {
+			unhealthy++
+		}
+	}
+
+	if unhealthy >= b.cfg.MinUnhealthyNodes && float64(unhealthy)/float64(observed) > b.cfg.MaxUnhealthyFraction {
+		// The counts are kept from the moment the breaker tripped so the reason stays stable.
+		if s.trip == nil {
+			s.trip = &systemicTrip{since: now, unhealthy: unhealthy, observed: observed}
+		}
+	} else {
+		s.trip = nil
+	}
+	return s.trip
+}
@@ -0,0 +1,114 @@
+package decision
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// window is a parsed maintenance window.
+type window struct {
+	days       map[time.Weekday]bool
+	start, end time.Duration
+	loc        *time.Location
+}
+
+// parseWindow validates and parses a maintenance window.
+func parseWindow(w v1alpha1.MaintenanceWindow) (window, error) {
+	var parsed window
+	var err error
+	if parsed.start, err = parseTimeOfDay(w.Start); err != nil {
+		return parsed, fmt.Errorf("invalid start %q: %w", w.Start, err)
+	}
+	if parsed.end, err = parseTimeOfDay(w.End); err != nil {
+		return parsed, fmt.Errorf("invalid end %q: %w", w.End, err)
+	}
+	parsed.loc = time.UTC
+	if w.TimeZone != "" {
+		if parsed.loc, err = time.LoadLocation(w.TimeZone); err != nil {
+			return parsed, fmt.Errorf("invalid time zone %q: %w", w.TimeZone, err)
+		}
+	}
+	if len(w.Days) > 0 {
+		parsed.days = make(map[time.Weekday]bool, len(w.Days))
+	}
+	for _, day := range w.Days {
+		weekday, ok := weekdays[day]
+		if !ok {
+			return parsed, fmt.Errorf("invalid day %q", day)
+		}
+		parsed.days[weekday] = true
+	}
+	return parsed, nil
+}
+
+var weekdays = map[v1alpha1.Weekday]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
+// parseTimeOfDay parses HH:MM into the offset from midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// startsOn reports whether the window starts on the weekday.
+func (w window) startsOn(day time.Weekday) bool {
+	return w.days == nil || w.days[day]
+}
+
+// contains reports whether the window is open at t.
+func (w window) contains(t time.Time) bool {
+	local := t.In(w.loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.loc)
+	offset := local.Sub(midnight)
+	yesterday := midnight.AddDate(0, 0, -1).Weekday()
+
+	switch {
+	case w.start == w.end:
+		return w.startsOn(local.Weekday())
+	case w.start < w.end:
+		return w.startsOn(local.Weekday()) && offset >= w.start && offset < w.end
+	default:
+		// The window runs past midnight.
+		return (w.startsOn(local.Weekday()) && offset >= w.start) || (w.startsOn(yesterday) && offset < w.end)
+	}
+}
+
+// ValidateWindows checks that all maintenance windows can be parsed.
+func ValidateWindows(windows []v1alpha1.MaintenanceWindow) error {
+	for i, w := range windows {
+		if _, err := parseWindow(w); err != nil {
+			return fmt.Errorf("invalid maintenance window %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// InWindow reports whether t falls into any of the maintenance windows. It is always true
+// when there are no windows.
+func InWindow(windows []v1alpha1.MaintenanceWindow, t time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+	for _, w := range windows {
+		parsed, err := parseWindow(w)
+		if err != nil {
+			return false, err
+		}
+		if parsed.contains(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
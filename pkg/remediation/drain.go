@@ -0,0 +1,209 @@
+package remediation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// maxParallelEvictions bounds the eviction requests a drain has in flight at once.
+	maxParallelEvictions = 10
+
+	// evictionRetryDelay and maxEvictionRetryDelay space out the drain passes retrying
+	// evictions refused with 429 Too Many Requests, usually by a PodDisruptionBudget.
+	evictionRetryDelay    = time.Second
+	maxEvictionRetryDelay = 30 * time.Second
+)
+
+// EvictionRetryDelay returns how long to wait before retrying evictions that were refused in
+// as many consecutive drain passes: a second after the first, doubling up to 30 seconds.
+func EvictionRetryDelay(refusedPasses int) time.Duration {
+	delay := evictionRetryDelay
+	for i := 1; i < refusedPasses && delay < maxEvictionRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxEvictionRetryDelay)
+}
+
+// DrainResult describes how far a drain got.
+type DrainResult struct {
+	// Evicted are the pods whose eviction was accepted, as namespace/name.
+	Evicted []string
+
+	// Blocked are the pods whose eviction was refused.
+	Blocked []BlockedPod
+
+	// Failed are the pods whose eviction failed for another reason.
+	Failed []FailedPod
+
+	// Remaining are the pods still on the node after the pass, as namespace/name, including
+	// evicted pods that are still terminating.
+	Remaining []string
+}
+
+// BlockedPod is a pod whose eviction was refused.
+type BlockedPod struct {
+	// Pod is the pod as namespace/name.
+	Pod string
+
+	// Budgets are the names of the PodDisruptionBudgets selecting the pod.
+	Budgets []string
+
+	// Message is the API server's reason for refusing the eviction.
+	Message string
+}
+
+// FailedPod is a pod whose eviction failed.
+type FailedPod struct {
+	// Pod is the pod as namespace/name.
+	Pod string
+
+	// Err is the eviction error.
+	Err error
+}
+
+// Complete reports whether no pods are left on the node.
+func (r *DrainResult) Complete() bool {
+	return len(r.Remaining) == 0
+}
+
+// String summarizes the result, naming the blocked pods and their budgets.
+func (r *DrainResult) String() string {
+	if r.Complete() {
+		return fmt.Sprintf("drained, evicted %d pods", len(r.Evicted))
+	}
+	parts := []string{fmt.Sprintf("%d pods remaining", len(r.Remaining))}
+	for _, b := range r.Blocked {
+		if len(b.Budgets) > 0 {
+			parts = append(parts, fmt.Sprintf("%s blocked by PodDisruptionBudget %s", b.Pod, strings.Join(b.Budgets, ", ")))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s blocked: %s", b.Pod, b.Message))
+		}
+	}
+	for _, f := range r.Failed {
+		parts = append(parts, fmt.Sprintf("%s failed: %v", f.Pod, f.Err))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DrainNode makes one pass at draining the node: it evicts the pods on it, in parallel, but
+// neither waits for them to terminate nor retries refused evictions, so a drain takes as many
+// passes as it needs until the result is complete. Pods already terminating are not evicted
+// again. Evictions refused with 429 Too Many Requests, e.g. by a PodDisruptionBudget, are
+// reported as blocked together with the budgets selecting the pods; see EvictionRetryDelay for
+// spacing out the passes retrying them. Any other eviction failure is returned as an error
+// alongside the result.
+func (e *Executor) DrainNode(ctx context.Context, nodeName string) (*DrainResult, error) {
+	pods, err := e.PodsToEvict(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DrainResult{}
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+		sem  = make(chan struct{}, maxParallelEvictions)
+	)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := pod.Namespace + "/" + pod.Name
+			sem <- struct{}{}
+			refused, err := e.evict(ctx, pod)
+			<-sem
+			var budgets []string
+			if refused != nil {
+				budgets = e.budgetsFor(ctx, pod)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case refused != nil:
+				result.Blocked = append(result.Blocked, BlockedPod{Pod: name, Budgets: budgets, Message: refused.Error()})
+			case err != nil:
+				result.Failed = append(result.Failed, FailedPod{Pod: name, Err: err})
+				errs = append(errs, fmt.Errorf("failed to evict pod %s: %w", name, err))
+			default:
+				result.Evicted = append(result.Evicted, name)
+			}
+		}()
+	}
+	wg.Wait()
+
+	remaining, err := e.PodsToEvict(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range remaining {
+		result.Remaining = append(result.Remaining, pod.Namespace+"/"+pod.Name)
+	}
+	sort.Strings(result.Evicted)
+	sort.Strings(result.Remaining)
+	sort.Slice(result.Blocked, func(i, j int) bool { return result.Blocked[i].Pod < result.Blocked[j].Pod })
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Pod < result.Failed[j].Pod })
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("failed to drain node %s: %w", nodeName, errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// evict evicts the pod. An eviction refused with 429 Too Many Requests is returned as refused.
+// A pod that is already gone counts as evicted.
+func (e *Executor) evict(ctx context.Context, pod *corev1.Pod) (refused error, err error) {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+	err = e.KubeClient.PolicyV1().Evictions(eviction.Namespace).Evict(ctx, eviction)
+	switch {
+	case err == nil || apierrors.IsNotFound(err):
+		return nil, nil
+	case apierrors.IsTooManyRequests(err):
+		return err, nil
+	default:
+		return nil, err
+	}
+}
+
+// budgetsFor returns the names of the PodDisruptionBudgets selecting the pod.
+func (e *Executor) budgetsFor(ctx context.Context, pod *corev1.Pod) []string {
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := e.Client.List(ctx, &pdbs, client.InNamespace(pod.Namespace)); err != nil {
+		return nil
+	}
+	var names []string
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			names = append(names, pdb.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
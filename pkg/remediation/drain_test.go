@@ -0,0 +1,183 @@
+package remediation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestExecutor_DrainNodeBlocked(t *testing.T) {
+	ctx := context.TODO()
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: "worker-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+	c := ctrlfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithObjects(pod("web-1", nil), pod("web-2", nil), pod("db-0", map[string]string{"app": "db"}), pdb).
+		Build()
+
+	// The API server refuses to evict db-0 and deletes the other pods once evicted.
+	kube := kubefake.NewSimpleClientset()
+	kube.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if eviction.Name == "db-0" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		victim := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: eviction.Name, Namespace: eviction.Namespace}}
+		if err := c.Delete(ctx, victim); err != nil {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, eviction.Name)
+		}
+		return true, nil, nil
+	})
+	executor := &Executor{Client: c, KubeClient: kube}
+
+	result, err := executor.DrainNode(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("DrainNode() error = %v", err)
+	}
+	if result.Complete() {
+		t.Errorf("DrainNode() completed with a blocked pod")
+	}
+	if got := strings.Join(result.Evicted, ","); got != "default/web-1,default/web-2" {
+		t.Errorf("DrainNode() evicted %s, want default/web-1,default/web-2", got)
+	}
+	if got := strings.Join(result.Remaining, ","); got != "default/db-0" {
+		t.Errorf("DrainNode() remaining %s, want default/db-0", got)
+	}
+	if len(result.Blocked) != 1 || result.Blocked[0].Pod != "default/db-0" || strings.Join(result.Blocked[0].Budgets, ",") != "db" {
+		t.Errorf("DrainNode() blocked = %+v, want default/db-0 by db", result.Blocked)
+	}
+	if want := "default/db-0 blocked by PodDisruptionBudget db"; !strings.Contains(result.String(), want) {
+		t.Errorf("DrainResult.String() = %q, want it to contain %q", result.String(), want)
+	}
+
+	// Once the budget allows it, the next pass completes the drain.
+	kube.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		victim := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"}}
+		return true, nil, c.Delete(ctx, victim)
+	})
+	result, err = executor.DrainNode(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("DrainNode() error = %v", err)
+	}
+	if !result.Complete() {
+		t.Errorf("DrainNode() = %v, want complete", result)
+	}
+}
+
+func TestExecutor_DrainNodeManyBlocked(t *testing.T) {
+	ctx := context.TODO()
+	objs := []client.Object{&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: "worker-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}}
+	// More pods are blocked than a drain evicts in parallel.
+	for i := 0; i < maxParallelEvictions+2; i++ {
+		objs = append(objs, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("db-%02d", i), Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "worker-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+	c := ctrlfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&corev1.Pod{}, "spec.nodeName", func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithObjects(objs...).
+		Build()
+
+	var evicted []string
+	kube := kubefake.NewSimpleClientset()
+	kube.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		evicted = append(evicted, eviction.Name)
+		if strings.HasPrefix(eviction.Name, "db-") {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		victim := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: eviction.Name, Namespace: eviction.Namespace}}
+		return true, nil, c.Delete(ctx, victim)
+	})
+	executor := &Executor{Client: c, KubeClient: kube}
+
+	// A pass tries every pod once and returns without waiting out the refusals.
+	start := time.Now()
+	result, err := executor.DrainNode(ctx, "worker-1")
+	if err != nil {
+		t.Fatalf("DrainNode() error = %v", err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("DrainNode() took %s, want a single round of evictions", took)
+	}
+	if len(evicted) != maxParallelEvictions+3 {
+		t.Errorf("DrainNode() made %d eviction requests, want one per pod", len(evicted))
+	}
+	if got := strings.Join(result.Evicted, ","); got != "default/web" {
+		t.Errorf("DrainNode() evicted %q, want default/web", got)
+	}
+	if len(result.Blocked) != maxParallelEvictions+2 {
+		t.Errorf("DrainNode() blocked %d pods, want %d", len(result.Blocked), maxParallelEvictions+2)
+	}
+}
+
+func TestEvictionRetryDelay(t *testing.T) {
+	tests := []struct {
+		refusedPasses int
+		want          time.Duration
+	}{
+		{refusedPasses: 1, want: time.Second},
+		{refusedPasses: 2, want: 2 * time.Second},
+		{refusedPasses: 5, want: 16 * time.Second},
+		{refusedPasses: 6, want: 30 * time.Second},
+		{refusedPasses: 100, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := EvictionRetryDelay(tt.refusedPasses); got != tt.want {
+			t.Errorf("EvictionRetryDelay(%d) = %s, want %s", tt.refusedPasses, got, tt.want)
+		}
+	}
+}
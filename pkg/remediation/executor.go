@@ -2,21 +2,37 @@ package remediation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/cloud"
 )
 
+// DefaultDrainTimeout bounds a drain when the policy does not set one.
+const DefaultDrainTimeout = 10 * time.Minute
+
+// TaintUnhealthy is the key of the PreferNoSchedule taint steering new pods away from degraded nodes.
+const TaintUnhealthy = "infra.example.com/unhealthy"
+
+// ErrNoInstance is returned by RebootNode and ReplaceNode when the node's instance cannot be
+// addressed, because no cloud provider is configured or the node has no valid spec.providerID.
+// Unlike a failed call to the provider, retrying does not help.
+var ErrNoInstance = errors.New("instance cannot be addressed")
+
 // Executor handles node remediation actions.
 type Executor struct {
 	Client     client.Client
 	KubeClient kubernetes.Interface
+
+	// Cloud reboots and replaces instances. Without it those actions fail.
+	Cloud cloud.Provider
 }
 
 // CordonNode marks the node as unschedulable.
@@ -35,39 +51,121 @@ func (e *Executor) CordonNode(ctx context.Context, nodeName string) error {
 	return nil
 }
 
-// DrainNode safely evicts all pods from a node.
-// This is a simplified implementation. Production grade would handle PDBs and timeouts more robustly.
-func (e *Executor) DrainNode(ctx context.Context, nodeName string) error {
-	// Safety: Ensure we don't block indefinitely.
-	// In production, this timeout should be configurable via the policy.
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-	defer cancel()
+// UncordonNode marks the node as schedulable again.
+func (e *Executor) UncordonNode(ctx context.Context, nodeName string) error {
+	patch := []byte(`{"spec":{"unschedulable":null}}`)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
+	if err := e.Client.Patch(ctx, node, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to uncordon node %s: %w", nodeName, err)
+	}
+	return nil
+}
 
-	// 1. List pods
+// TaintNode adds the TaintUnhealthy PreferNoSchedule taint to the node.
+func (e *Executor) TaintNode(ctx context.Context, nodeName string) error {
+	err := e.updateTaints(ctx, nodeName, func(taints []corev1.Taint) []corev1.Taint {
+		for _, t := range taints {
+			if t.Key == TaintUnhealthy && t.Effect == corev1.TaintEffectPreferNoSchedule {
+				return taints
+			}
+		}
+		return append(taints, corev1.Taint{Key: TaintUnhealthy, Effect: corev1.TaintEffectPreferNoSchedule})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to taint node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// UntaintNode removes the TaintUnhealthy taint from the node.
+func (e *Executor) UntaintNode(ctx context.Context, nodeName string) error {
+	err := e.updateTaints(ctx, nodeName, func(taints []corev1.Taint) []corev1.Taint {
+		kept := make([]corev1.Taint, 0, len(taints))
+		for _, t := range taints {
+			if t.Key != TaintUnhealthy {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	})
+	if err != nil {
+		return fmt.Errorf("failed to untaint node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// updateTaints replaces the node's taints with mutate's result. The taints are a list, so the
+// patch carries an optimistic lock to not drop taints added concurrently.
+func (e *Executor) updateTaints(ctx context.Context, nodeName string, mutate func([]corev1.Taint) []corev1.Taint) error {
+	var node corev1.Node
+	if err := e.Client.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+		return err
+	}
+	taints := mutate(append([]corev1.Taint(nil), node.Spec.Taints...))
+	if len(taints) == len(node.Spec.Taints) {
+		return nil
+	}
+	base := node.DeepCopy()
+	node.Spec.Taints = taints
+	return e.Client.Patch(ctx, &node, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+}
+
+// RebootNode reboots the node's instance, identified by its spec.providerID, through the cloud provider.
+func (e *Executor) RebootNode(ctx context.Context, node *corev1.Node) error {
+	if e.Cloud == nil {
+		return fmt.Errorf("failed to reboot node %s: %w: no cloud provider configured", node.Name, ErrNoInstance)
+	}
+	id, err := cloud.InstanceID(node.Spec.ProviderID)
+	if err != nil {
+		return fmt.Errorf("failed to reboot node %s: %w: %w", node.Name, ErrNoInstance, err)
+	}
+	if err := e.Cloud.RebootNode(ctx, id); err != nil {
+		return fmt.Errorf("failed to reboot node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// ReplaceNode replaces the node's instance, identified by its spec.providerID, through the cloud
+// provider. The replacement joins the pool as a new node.
+func (e *Executor) ReplaceNode(ctx context.Context, node *corev1.Node) error {
+	if e.Cloud == nil {
+		return fmt.Errorf("failed to replace node %s: %w: no cloud provider configured", node.Name, ErrNoInstance)
+	}
+	id, err := cloud.InstanceID(node.Spec.ProviderID)
+	if err != nil {
+		return fmt.Errorf("failed to replace node %s: %w: %w", node.Name, ErrNoInstance, err)
+	}
+	if err := e.Cloud.ReplaceNode(ctx, id); err != nil {
+		return fmt.Errorf("failed to replace node %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// PodsToEvict returns the pods on the node a drain has to remove, including those already
+// terminating. The node is drained once it returns none.
+func (e *Executor) PodsToEvict(ctx context.Context, nodeName string) ([]corev1.Pod, error) {
 	pods := &corev1.PodList{}
 	if err := e.Client.List(ctx, pods, client.MatchingFields{"spec.nodeName": nodeName}); err != nil {
-		return fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
+		return nil, fmt.Errorf("failed to list pods on node %s: %w", nodeName, err)
 	}
 
+	var evict []corev1.Pod
 	for _, pod := range pods.Items {
 		// Skip DaemonSets and Static Pods
 		if isDaemonSet(&pod) || isStaticPod(&pod) {
 			continue
 		}
-
-		// Evict
-		eviction := &policyv1.Eviction{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      pod.Name,
-				Namespace: pod.Namespace,
-			},
-		}
-		if err := e.KubeClient.PolicyV1().Evictions(eviction.Namespace).Evict(ctx, eviction); err != nil {
-			return fmt.Errorf("failed to evict pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		// Finished pods hold no resources.
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
 		}
+		evict = append(evict, pod)
 	}
-
-	return nil
+	return evict, nil
 }
 
 func isDaemonSet(pod *corev1.Pod) bool {
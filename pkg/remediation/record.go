@@ -0,0 +1,60 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// AnnotationLastRemediation holds the node's last RemediationRecord as JSON.
+// Keeping it on the node lets the cooldown survive controller restarts.
+const AnnotationLastRemediation = "infra.example.com/last-remediation"
+
+// ActionCordonAndDrain is the record action for cordoning and draining a node.
+const ActionCordonAndDrain = "CordonAndDrain"
+
+// RecordRemediation stores the record in the node's annotations.
+func (e *Executor) RecordRemediation(ctx context.Context, rec v1alpha1.RemediationRecord) error {
+	value, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode remediation record: %w", err)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{AnnotationLastRemediation: string(value)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode remediation record: %w", err)
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: rec.Node,
+		},
+	}
+	if err := e.Client.Patch(ctx, node, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to record remediation on node %s: %w", rec.Node, err)
+	}
+	return nil
+}
+
+// LastRemediation reads the node's last remediation record. It returns nil if the node
+// was never remediated.
+func LastRemediation(node *corev1.Node) (*v1alpha1.RemediationRecord, error) {
+	value, ok := node.Annotations[AnnotationLastRemediation]
+	if !ok {
+		return nil, nil
+	}
+	var rec v1alpha1.RemediationRecord
+	if err := json.Unmarshal([]byte(value), &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse annotation %s on node %s: %w", AnnotationLastRemediation, node.Name, err)
+	}
+	return &rec, nil
+}
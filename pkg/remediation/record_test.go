@@ -0,0 +1,55 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+func TestExecutor_RecordRemediation(t *testing.T) {
+	ctx := context.TODO()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+	executor := &Executor{Client: c}
+
+	rec := v1alpha1.RemediationRecord{
+		Node:    "worker-1",
+		Action:  ActionCordonAndDrain,
+		Outcome: v1alpha1.RemediationSucceeded,
+		Time:    metav1.NewTime(time.Now().Truncate(time.Second)),
+	}
+	if err := executor.RecordRemediation(ctx, rec); err != nil {
+		t.Fatalf("RecordRemediation() error = %v", err)
+	}
+
+	var got corev1.Node
+	if err := c.Get(ctx, client.ObjectKey{Name: "worker-1"}, &got); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	read, err := LastRemediation(&got)
+	if err != nil {
+		t.Fatalf("LastRemediation() error = %v", err)
+	}
+	if read == nil || read.Outcome != rec.Outcome || !read.Time.Equal(&rec.Time) {
+		t.Errorf("LastRemediation() = %+v, want %+v", read, rec)
+	}
+
+	if read, err := LastRemediation(node); read != nil || err != nil {
+		t.Errorf("LastRemediation() of unremediated node = %v, %v, want nil", read, err)
+	}
+	node.Annotations = map[string]string{AnnotationLastRemediation: "not json"}
+	if _, err := LastRemediation(node); err == nil {
+		t.Errorf("LastRemediation() of corrupt annotation error = nil, want error")
+	}
+}
@@ -0,0 +1,66 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// AnnotationLadderStep holds the node's LadderStep as JSON.
+const AnnotationLadderStep = "infra.example.com/ladder-step"
+
+// LadderStep is the step of its policy's escalation ladder a node is on.
+type LadderStep struct {
+	Index  int                        `json:"index"`
+	Action v1alpha1.RemediationAction `json:"action"`
+	Since  metav1.Time                `json:"since"`
+}
+
+// SetLadderStep stores the step in the node's annotations. A nil step removes it.
+func (e *Executor) SetLadderStep(ctx context.Context, nodeName string, step *LadderStep) error {
+	var value interface{}
+	if step != nil {
+		raw, err := json.Marshal(step)
+		if err != nil {
+			return fmt.Errorf("failed to encode ladder step: %w", err)
+		}
+		value = string(raw)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{AnnotationLadderStep: value},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode ladder step: %w", err)
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
+	if err := e.Client.Patch(ctx, node, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("failed to record ladder step on node %s: %w", nodeName, err)
+	}
+	return nil
+}
+
+// CurrentLadderStep reads the node's ladder step. It returns nil if the node is on none.
+func CurrentLadderStep(node *corev1.Node) (*LadderStep, error) {
+	value, ok := node.Annotations[AnnotationLadderStep]
+	if !ok {
+		return nil, nil
+	}
+	var step LadderStep
+	if err := json.Unmarshal([]byte(value), &step); err != nil {
+		return nil, fmt.Errorf("failed to parse annotation %s on node %s: %w", AnnotationLadderStep, node.Name, err)
+	}
+	return &step, nil
+}
@@ -0,0 +1,76 @@
+package remediation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+func TestExecutor_LadderStep(t *testing.T) {
+	ctx := context.TODO()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+	executor := &Executor{Client: c}
+
+	get := func() *corev1.Node {
+		var got corev1.Node
+		if err := c.Get(ctx, client.ObjectKey{Name: "worker-1"}, &got); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return &got
+	}
+
+	step := &LadderStep{Index: 1, Action: v1alpha1.RemediationCordon, Since: metav1.NewTime(time.Now().Truncate(time.Second))}
+	if err := executor.SetLadderStep(ctx, "worker-1", step); err != nil {
+		t.Fatalf("SetLadderStep() error = %v", err)
+	}
+	read, err := CurrentLadderStep(get())
+	if err != nil {
+		t.Fatalf("CurrentLadderStep() error = %v", err)
+	}
+	if read == nil || read.Index != step.Index || read.Action != step.Action || !read.Since.Equal(&step.Since) {
+		t.Errorf("CurrentLadderStep() = %+v, want %+v", read, step)
+	}
+	if err := executor.SetLadderStep(ctx, "worker-1", nil); err != nil {
+		t.Fatalf("SetLadderStep(nil) error = %v", err)
+	}
+	if read, err := CurrentLadderStep(get()); read != nil || err != nil {
+		t.Errorf("CurrentLadderStep() after removal = %v, %v, want nil", read, err)
+	}
+
+	// Tainting is idempotent and untainting keeps foreign taints.
+	for i := 0; i < 2; i++ {
+		if err := executor.TaintNode(ctx, "worker-1"); err != nil {
+			t.Fatalf("TaintNode() error = %v", err)
+		}
+	}
+	if taints := get().Spec.Taints; len(taints) != 2 || taints[1].Key != TaintUnhealthy || taints[1].Effect != corev1.TaintEffectPreferNoSchedule {
+		t.Errorf("taints after TaintNode() = %v", taints)
+	}
+	if err := executor.UntaintNode(ctx, "worker-1"); err != nil {
+		t.Fatalf("UntaintNode() error = %v", err)
+	}
+	if taints := get().Spec.Taints; len(taints) != 1 || taints[0].Key != "dedicated" {
+		t.Errorf("taints after UntaintNode() = %v", taints)
+	}
+
+	if err := executor.ReplaceNode(ctx, get()); err == nil {
+		t.Errorf("ReplaceNode() without cloud provider error = nil, want error")
+	}
+}
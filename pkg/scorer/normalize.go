@@ -0,0 +1,130 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// Transform maps a raw signal value, in whatever unit the collector reports, to 0.0 (healthy) - 1.0 (unhealthy).
+type Transform interface {
+	Normalize(raw float64) float64
+}
+
+// LinearTransform maps Floor to 0.0 and Ceiling to 1.0, linearly in between.
+type LinearTransform struct {
+	Floor   float64
+	Ceiling float64
+}
+
+func (t LinearTransform) Normalize(raw float64) float64 {
+	return clamp((raw - t.Floor) / (t.Ceiling - t.Floor))
+}
+
+// LogTransform maps Floor to 0.0 and Ceiling to 1.0 on a logarithmic scale, for signals
+// spanning several orders of magnitude such as drops per second. Floor must be positive.
+type LogTransform struct {
+	Floor   float64
+	Ceiling float64
+}
+
+func (t LogTransform) Normalize(raw float64) float64 {
+	if raw <= t.Floor {
+		return 0.0
+	}
+	return clamp(math.Log(raw/t.Floor) / math.Log(t.Ceiling/t.Floor))
+}
+
+// LogisticTransform is an S-curve that is 0.5 at Midpoint. Steepness controls how quickly
+// it moves from 0.0 to 1.0 around the midpoint.
+type LogisticTransform struct {
+	Midpoint  float64
+	Steepness float64
+}
+
+func (t LogisticTransform) Normalize(raw float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-t.Steepness*(raw-t.Midpoint)))
+}
+
+// Step is a threshold of a StepTransform.
+type Step struct {
+	Threshold float64
+	Score     float64
+}
+
+// StepTransform returns the score of the highest threshold the raw value reaches, or 0.0 below all of them.
+// Steps must be sorted by ascending threshold.
+type StepTransform struct {
+	Steps []Step
+}
+
+func (t StepTransform) Normalize(raw float64) float64 {
+	var score float64
+	for _, s := range t.Steps {
+		if raw < s.Threshold {
+			break
+		}
+		score = s.Score
+	}
+	return clamp(score)
+}
+
+// TransformsFromSpec builds the per-metric transforms configured in a policy.
+func TransformsFromSpec(spec []v1alpha1.MetricNormalization) (map[MetricName]Transform, error) {
+	transforms := make(map[MetricName]Transform, len(spec))
+	for _, n := range spec {
+		if !IsKnownMetric(MetricName(n.Metric)) {
+			return nil, fmt.Errorf("normalization of unknown metric %s, known metrics are %v", n.Metric, KnownMetrics())
+		}
+		t, err := newTransform(n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid normalization for metric %s: %w", n.Metric, err)
+		}
+		transforms[MetricName(n.Metric)] = t
+	}
+	return transforms, nil
+}
+
+func newTransform(n v1alpha1.MetricNormalization) (Transform, error) {
+	switch n.Type {
+	case v1alpha1.NormalizationLinear:
+		if n.Ceiling <= n.Floor {
+			return nil, fmt.Errorf("ceiling %v must be greater than floor %v", n.Ceiling, n.Floor)
+		}
+		return LinearTransform{Floor: n.Floor, Ceiling: n.Ceiling}, nil
+	case v1alpha1.NormalizationLog:
+		if n.Floor <= 0 || n.Ceiling <= n.Floor {
+			return nil, fmt.Errorf("log scale needs 0 < floor < ceiling, got floor %v and ceiling %v", n.Floor, n.Ceiling)
+		}
+		return LogTransform{Floor: n.Floor, Ceiling: n.Ceiling}, nil
+	case v1alpha1.NormalizationLogistic:
+		if n.Steepness <= 0 {
+			return nil, fmt.Errorf("steepness %v must be positive", n.Steepness)
+		}
+		return LogisticTransform{Midpoint: n.Midpoint, Steepness: n.Steepness}, nil
+	case v1alpha1.NormalizationStep:
+		if len(n.Steps) == 0 {
+			return nil, fmt.Errorf("at least one step is required")
+		}
+		steps := make([]Step, 0, len(n.Steps))
+		for _, s := range n.Steps {
+			steps = append(steps, Step{Threshold: s.Threshold, Score: s.Score})
+		}
+		sort.Slice(steps, func(i, j int) bool { return steps[i].Threshold < steps[j].Threshold })
+		return StepTransform{Steps: steps}, nil
+	default:
+		return nil, fmt.Errorf("unknown normalization type %q", n.Type)
+	}
+}
+
+func clamp(v float64) float64 {
+	if v > 1.0 {
+		return 1.0
+	}
+	if v < 0.0 {
+		return 0.0
+	}
+	return v
+}
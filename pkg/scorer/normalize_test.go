@@ -0,0 +1,70 @@
+package scorer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+func TestTransforms_Normalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform Transform
+		raw       float64
+		want      float64
+	}{
+		{name: "Linear below floor", transform: LinearTransform{Floor: 10, Ceiling: 110}, raw: 5, want: 0},
+		{name: "Linear in range", transform: LinearTransform{Floor: 10, Ceiling: 110}, raw: 60, want: 0.5},
+		{name: "Linear above ceiling", transform: LinearTransform{Floor: 10, Ceiling: 110}, raw: 500, want: 1},
+		{name: "Log one decade of two", transform: LogTransform{Floor: 1, Ceiling: 100}, raw: 10, want: 0.5},
+		{name: "Log at floor", transform: LogTransform{Floor: 1, Ceiling: 100}, raw: 0, want: 0},
+		{name: "Logistic at midpoint", transform: LogisticTransform{Midpoint: 200, Steepness: 0.1}, raw: 200, want: 0.5},
+		{name: "Step below all", transform: StepTransform{Steps: []Step{{Threshold: 1, Score: 0.5}, {Threshold: 5, Score: 1}}}, raw: 0.5, want: 0},
+		{name: "Step between", transform: StepTransform{Steps: []Step{{Threshold: 1, Score: 0.5}, {Threshold: 5, Score: 1}}}, raw: 3, want: 0.5},
+		{name: "Step at threshold", transform: StepTransform{Steps: []Step{{Threshold: 1, Score: 0.5}, {Threshold: 5, Score: 1}}}, raw: 5, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transform.Normalize(tt.raw); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Normalize(%v) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformsFromSpec(t *testing.T) {
+	transforms, err := TransformsFromSpec([]v1alpha1.MetricNormalization{
+		{Metric: "disk_io_wait", Type: v1alpha1.NormalizationLinear, Floor: 20, Ceiling: 220},
+		{Metric: "network_drops", Type: v1alpha1.NormalizationStep, Steps: []v1alpha1.NormalizationThreshold{
+			{Threshold: 100, Score: 1},
+			{Threshold: 10, Score: 0.3},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("TransformsFromSpec() error = %v", err)
+	}
+
+	s := NewScorer(map[MetricName]float64{MetricDiskIOWait: 1, MetricNetworkDrops: 1})
+	s.Transforms = transforms
+	// 120ms of IO wait is halfway, 50 drops/sec is in the first step.
+	got := s.CalculateScore(map[MetricName]float64{MetricDiskIOWait: 120, MetricNetworkDrops: 50})
+	if want := 0.4; math.Abs(got-want) > 1e-9 {
+		t.Errorf("CalculateScore() = %v, want %v", got, want)
+	}
+
+	invalid := [][]v1alpha1.MetricNormalization{
+		{{Metric: "disk_io_wait", Type: v1alpha1.NormalizationLinear, Floor: 1, Ceiling: 1}},
+		{{Metric: "disk_io_wait", Type: v1alpha1.NormalizationLog, Floor: 0, Ceiling: 10}},
+		{{Metric: "disk_io_wait", Type: v1alpha1.NormalizationLogistic, Midpoint: 5}},
+		{{Metric: "disk_io_wait", Type: v1alpha1.NormalizationStep}},
+		{{Metric: "disk_io_wait", Type: "Cubic"}},
+		{{Metric: "disk_io_wiat", Type: v1alpha1.NormalizationLinear, Floor: 0, Ceiling: 1}},
+	}
+	for _, spec := range invalid {
+		if _, err := TransformsFromSpec(spec); err == nil {
+			t.Errorf("TransformsFromSpec(%+v) error = nil, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package scorer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
+)
+
+// NewPolicyScorer builds the scorer configured by a policy's scoring spec.
+// Policies that do not set weights are scored with defaultWeights.
+func NewPolicyScorer(spec v1alpha1.Scoring, defaultWeights map[MetricName]float64) (*Scorer, error) {
+	weights := make(map[MetricName]float64, len(defaultWeights))
+	if len(spec.Weights) == 0 {
+		for metric, w := range defaultWeights {
+			weights[metric] = w
+		}
+	} else {
+		if err := ValidateWeights(spec.Weights); err != nil {
+			return nil, err
+		}
+		for metric, w := range spec.Weights {
+			weights[MetricName(metric)] = w
+		}
+	}
+
+	transforms, err := TransformsFromSpec(spec.Normalization)
+	if err != nil {
+		return nil, err
+	}
+	s := NewScorer(weights)
+	s.Transforms = transforms
+	return s, nil
+}
+
+// ValidateWeights checks that all weights are of known metrics, finite and non-negative and that
+// at least one is positive. A weight of a metric no collector reports would never be covered and
+// leave the node's health unknown.
+func ValidateWeights(weights map[string]float64) error {
+	var total float64
+	for metric, w := range weights {
+		if metric == "" {
+			return fmt.Errorf("weight with empty metric name")
+		}
+		if !IsKnownMetric(MetricName(metric)) {
+			return fmt.Errorf("weight of unknown metric %s, known metrics are %v", metric, KnownMetrics())
+		}
+		if math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return fmt.Errorf("weight of metric %s must be a non-negative number, got %v", metric, w)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("at least one weight must be positive")
+	}
+	return nil
+}
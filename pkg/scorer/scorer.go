@@ -1,5 +1,7 @@
 package scorer
 
+import "sort"
+
 // MetricName represents the name of a health signal.
 type MetricName string
 
@@ -9,17 +11,57 @@ const (
 	MetricKubeletErrors  MetricName = "kubelet_errors"
 	MetricMemoryPressure MetricName = "memory_pressure"
 	MetricConditionFlaps MetricName = "condition_flaps"
+
+	// Signals reported by node-problem-detector.
+	MetricKernelDeadlock            MetricName = "kernel_deadlock"
+	MetricReadonlyFilesystem        MetricName = "readonly_filesystem"
+	MetricFrequentKubeletRestart    MetricName = "frequent_kubelet_restart"
+	MetricFrequentContainerdRestart MetricName = "frequent_containerd_restart"
 )
 
+// KnownMetrics returns every metric the collectors report, in a stable order.
+func KnownMetrics() []MetricName {
+	return []MetricName{
+		MetricDiskIOWait,
+		MetricNetworkDrops,
+		MetricKubeletErrors,
+		MetricMemoryPressure,
+		MetricConditionFlaps,
+		MetricKernelDeadlock,
+		MetricReadonlyFilesystem,
+		MetricFrequentKubeletRestart,
+		MetricFrequentContainerdRestart,
+	}
+}
+
+// IsKnownMetric reports whether a collector reports the metric.
+func IsKnownMetric(metric MetricName) bool {
+	for _, known := range KnownMetrics() {
+		if metric == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Scorer calculates the health score of a node based on signals and weights.
 type Scorer struct {
 	Weights map[MetricName]float64
+
+	// Transforms normalizes raw signal values per metric. Metrics without a transform
+	// are expected to be reported already normalized and are only clamped.
+	Transforms map[MetricName]Transform
+
+	// Floors lifts the score to at least the floor times the normalized value of a weighted
+	// metric, so a critical signal alone can make a node unhealthy whatever its share of the weight.
+	Floors map[MetricName]float64
 }
 
-// NewScorer creates a new Scorer with the provided weights.
+// NewScorer creates a new Scorer with the provided weights and the CriticalFloors.
 func NewScorer(weights map[MetricName]float64) *Scorer {
 	s := &Scorer{
 		Weights: weights,
+		Floors:  CriticalFloors(),
 	}
 	s.normalizeWeights()
 	return s
@@ -27,13 +69,40 @@ func NewScorer(weights map[MetricName]float64) *Scorer {
 
 // DefaultScorer returns a scorer with default standard weights.
 func DefaultScorer() *Scorer {
-	return NewScorer(map[MetricName]float64{
+	return NewScorer(DefaultWeights())
+}
+
+// DefaultWeights returns a fresh copy of the default standard weights.
+func DefaultWeights() map[MetricName]float64 {
+	return map[MetricName]float64{
 		MetricDiskIOWait:     0.30,
 		MetricNetworkDrops:   0.20,
 		MetricKubeletErrors:  0.20,
 		MetricMemoryPressure: 0.15,
 		MetricConditionFlaps: 0.15,
-	})
+	}
+}
+
+// NodeProblemDetectorWeights returns weights for the node-problem-detector signals.
+// They are kept out of DefaultWeights because on clusters without node-problem-detector
+// the signals are never reported and would only dilute the other weights.
+func NodeProblemDetectorWeights() map[MetricName]float64 {
+	return map[MetricName]float64{
+		MetricKernelDeadlock:            0.30,
+		MetricReadonlyFilesystem:        0.20,
+		MetricFrequentKubeletRestart:    0.10,
+		MetricFrequentContainerdRestart: 0.10,
+	}
+}
+
+// CriticalFloors returns the score floors of signals that mean a node is broken on their own.
+// A kernel deadlock or a read-only root filesystem leaves the kubelet heartbeating and the other
+// signals clean, so weighted alongside them they could never reach an unhealthy score.
+func CriticalFloors() map[MetricName]float64 {
+	return map[MetricName]float64{
+		MetricKernelDeadlock:     1.0,
+		MetricReadonlyFilesystem: 1.0,
+	}
 }
 
 // normalizeWeights ensures the weights sum to 1.0.
@@ -50,23 +119,77 @@ func (s *Scorer) normalizeWeights() {
 	}
 }
 
-// CalculateScore computes the weighted health score.
+// CalculateScore computes the weighted health score, lifted to the highest floor reached.
 // Returns a score between 0.0 (healthy) and 1.0 (unhealthy).
 func (s *Scorer) CalculateScore(signals map[MetricName]float64) float64 {
 	var totalScore float64
 
+	for _, contribution := range s.Breakdown(signals) {
+		totalScore += contribution
+	}
+
+	return totalScore
+}
+
+// Breakdown returns each present metric's contribution to the score calculated by CalculateScore.
+// A metric whose floor lifts the score is credited with the lift.
+func (s *Scorer) Breakdown(signals map[MetricName]float64) map[MetricName]float64 {
+	contributions := make(map[MetricName]float64, len(s.Weights))
+	var weighted float64
 	for metric, weight := range s.Weights {
 		if val, ok := signals[metric]; ok {
-			// Clamp signal value between 0 and 1
-			if val > 1.0 {
-				val = 1.0
-			}
-			if val < 0.0 {
-				val = 0.0
-			}
-			totalScore += val * weight
+			contributions[metric] = s.Normalize(metric, val) * weight
+			weighted += contributions[metric]
 		}
 	}
 
-	return totalScore
+	var lift float64
+	var lifting MetricName
+	for metric, floor := range s.Floors {
+		val, ok := signals[metric]
+		if weight := s.Weights[metric]; !ok || weight <= 0 {
+			continue
+		}
+		if l := floor*s.Normalize(metric, val) - weighted; l > lift || (l == lift && l > 0 && metric < lifting) {
+			lift, lifting = l, metric
+		}
+	}
+	if lift > 0 {
+		contributions[lifting] += lift
+	}
+	return contributions
+}
+
+// Normalize maps a raw signal value to 0.0 - 1.0 using the metric's transform.
+func (s *Scorer) Normalize(metric MetricName, raw float64) float64 {
+	if t, ok := s.Transforms[metric]; ok {
+		return clamp(t.Normalize(raw))
+	}
+	return clamp(raw)
+}
+
+// Metrics returns the weighted metrics in a stable order.
+func (s *Scorer) Metrics() []MetricName {
+	metrics := make([]MetricName, 0, len(s.Weights))
+	for metric := range s.Weights {
+		metrics = append(metrics, metric)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i] < metrics[j] })
+	return metrics
+}
+
+// Coverage returns the share of the total weight (0.0 - 1.0) carried by the metrics present in signals.
+// A low coverage means the score is mostly made of missing data and should not be trusted.
+func (s *Scorer) Coverage(signals map[MetricName]float64) float64 {
+	var covered, total float64
+	for metric, weight := range s.Weights {
+		total += weight
+		if _, ok := signals[metric]; ok {
+			covered += weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return covered / total
 }
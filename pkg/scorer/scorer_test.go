@@ -1,7 +1,12 @@
 package scorer
 
 import (
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/example/self-healing-nodepool/pkg/apis/v1alpha1"
 )
 
 func TestScorer_CalculateScore(t *testing.T) {
@@ -58,3 +63,144 @@ func TestScorer_CalculateScore(t *testing.T) {
 		})
 	}
 }
+
+func TestScorer_Coverage(t *testing.T) {
+	s := NewScorer(map[MetricName]float64{
+		"signal1": 3,
+		"signal2": 1,
+	})
+	tests := []struct {
+		name    string
+		signals map[MetricName]float64
+		want    float64
+	}{
+		{name: "All present", signals: map[MetricName]float64{"signal1": 0, "signal2": 0}, want: 1.0},
+		{name: "Heavy signal missing", signals: map[MetricName]float64{"signal2": 0.5}, want: 0.25},
+		{name: "Unweighted signal ignored", signals: map[MetricName]float64{"other": 1}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Coverage(tt.signals); got != tt.want {
+				t.Errorf("Scorer.Coverage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScorer_Breakdown(t *testing.T) {
+	s := NewScorer(map[MetricName]float64{
+		"signal1": 3,
+		"signal2": 1,
+	})
+	signals := map[MetricName]float64{"signal1": 0.5, "other": 1}
+	got := s.Breakdown(signals)
+	want := map[MetricName]float64{"signal1": 0.375}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scorer.Breakdown() = %v, want %v", got, want)
+	}
+	if total := s.CalculateScore(signals); math.Abs(total-got["signal1"]) > 1e-9 {
+		t.Errorf("Scorer.Breakdown() sums to %v, CalculateScore() = %v", got["signal1"], total)
+	}
+}
+
+func TestScorer_CriticalFloors(t *testing.T) {
+	weights := DefaultWeights()
+	for metric, w := range NodeProblemDetectorWeights() {
+		weights[metric] = w
+	}
+	s := NewScorer(weights)
+	clean := map[MetricName]float64{
+		MetricDiskIOWait:         0,
+		MetricNetworkDrops:       0,
+		MetricKubeletErrors:      0,
+		MetricMemoryPressure:     0,
+		MetricConditionFlaps:     0,
+		MetricKernelDeadlock:     0,
+		MetricReadonlyFilesystem: 0,
+	}
+	withSignal := func(metric MetricName, value float64) map[MetricName]float64 {
+		signals := make(map[MetricName]float64, len(clean))
+		for m, v := range clean {
+			signals[m] = v
+		}
+		signals[metric] = value
+		return signals
+	}
+
+	tests := []struct {
+		name    string
+		signals map[MetricName]float64
+		want    float64
+	}{
+		{name: "Clean node", signals: clean, want: 0},
+		{name: "Kernel deadlock alone", signals: withSignal(MetricKernelDeadlock, 1.0), want: 1.0},
+		{name: "Read-only filesystem events", signals: withSignal(MetricReadonlyFilesystem, 0.5), want: 0.5},
+		{name: "Non-critical signal is only weighted", signals: withSignal(MetricDiskIOWait, 1.0), want: s.Weights[MetricDiskIOWait]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.CalculateScore(tt.signals)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Scorer.CalculateScore() = %v, want %v", got, tt.want)
+			}
+			var sum float64
+			for _, c := range s.Breakdown(tt.signals) {
+				sum += c
+			}
+			if math.Abs(sum-got) > 1e-9 {
+				t.Errorf("Scorer.Breakdown() sums to %v, CalculateScore() = %v", sum, got)
+			}
+		})
+	}
+
+	// A kernel deadlock alone reaches the default unhealthy threshold.
+	if got := s.CalculateScore(withSignal(MetricKernelDeadlock, 1.0)); got < 0.6 {
+		t.Errorf("kernel deadlock scores %v, below the unhealthy threshold of 0.6", got)
+	}
+
+	// Policies that do not weight a critical signal are not lifted by it.
+	s = NewScorer(DefaultWeights())
+	if got := s.CalculateScore(withSignal(MetricKernelDeadlock, 1.0)); got != 0 {
+		t.Errorf("unweighted kernel deadlock scores %v, want 0", got)
+	}
+}
+
+func TestNewPolicyScorer(t *testing.T) {
+	defaults := map[MetricName]float64{MetricDiskIOWait: 1, MetricNetworkDrops: 1}
+
+	s, err := NewPolicyScorer(v1alpha1.Scoring{}, defaults)
+	if err != nil {
+		t.Fatalf("NewPolicyScorer() error = %v", err)
+	}
+	if got := s.Weights[MetricDiskIOWait]; got != 0.5 {
+		t.Errorf("default weight = %v, want 0.5", got)
+	}
+	if defaults[MetricDiskIOWait] != 1 {
+		t.Errorf("NewPolicyScorer() modified the default weights")
+	}
+
+	s, err = NewPolicyScorer(v1alpha1.Scoring{Weights: map[string]float64{"disk_io_wait": 0.5, "kubelet_errors": 0.5}}, defaults)
+	if err != nil {
+		t.Fatalf("NewPolicyScorer() error = %v", err)
+	}
+	if _, ok := s.Weights[MetricNetworkDrops]; ok {
+		t.Errorf("policy weights were merged with the defaults: %v", s.Weights)
+	}
+
+	for _, weights := range []map[string]float64{
+		{"disk_io_wait": -1, "kubelet_errors": 2},
+		{"disk_io_wait": 0},
+		{"": 1},
+		{"disk_io_wait": 1, "cpu_presure": 1},
+	} {
+		if _, err := NewPolicyScorer(v1alpha1.Scoring{Weights: weights}, defaults); err == nil {
+			t.Errorf("NewPolicyScorer(%v) error = nil, want error", weights)
+		}
+	}
+
+	// A misspelt metric is reported instead of silently leaving its weight uncovered.
+	_, err = NewPolicyScorer(v1alpha1.Scoring{Weights: map[string]float64{"cpu_presure": 1}}, defaults)
+	if err == nil || !strings.Contains(err.Error(), "unknown metric cpu_presure") {
+		t.Errorf("NewPolicyScorer() error = %v, want unknown metric cpu_presure", err)
+	}
+}